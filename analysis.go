@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// normalTolerance is the angle in degrees a
+// stored facet normal may be off the winding
+// before it is reported as disagreeing.
+const normalTolerance = 45
+
+// Overlay colours of the MeshReport defects.
+var (
+	boundaryColor    = mgl32.Vec4{1, 0, 0, 1}
+	nonManifoldColor = mgl32.Vec4{1, 0, 1, 1}
+	windingColor     = mgl32.Vec4{1, 0.5, 0, 1}
+	degenerateColor  = mgl32.Vec4{1, 1, 0, 1}
+	facetColor       = mgl32.Vec4{0, 0.4, 1, 1}
+)
+
+// MeshEdge is an edge of the welded mesh
+// and the facets using it.
+type MeshEdge struct {
+	A, B      mgl32.Vec3
+	Triangles []int
+}
+
+// MeshReport lists what keeps a solid from
+// being printable. Edge defects refer to the
+// welded mesh, facet defects to the indices
+// of STL.Triangles.
+type MeshReport struct {
+	Triangles, Vertices int
+
+	// BoundaryEdges are used by one facet only:
+	// the surface has a hole there.
+	BoundaryEdges []MeshEdge
+
+	// NonManifoldEdges are shared by more
+	// than two facets.
+	NonManifoldEdges []MeshEdge
+
+	// WindingConflicts are edges whose two facets
+	// traverse them in the same direction, so one
+	// of them is wound the wrong way round.
+	WindingConflicts []MeshEdge
+
+	// Degenerate facets have (near) zero area.
+	Degenerate []int
+
+	// Duplicates are facets repeating the
+	// corners of an earlier one; they are left
+	// out of the edge checks, which would
+	// otherwise all flag them again.
+	Duplicates []int
+
+	// FlippedNormals are facets whose stored
+	// normal disagrees with their winding.
+	FlippedNormals []int
+}
+
+// Analyze
+// Welds the solid within epsilon and checks
+// its topology and facets; see MeshReport.
+func (s *STL) Analyze(epsilon float32) *MeshReport {
+	mesh := s.Indexed(epsilon)
+	report := &MeshReport{
+		Triangles: len(s.Triangles),
+		Vertices:  len(mesh.Positions),
+	}
+
+	type use struct {
+		triangle int
+		forward  bool
+	}
+
+	edges := map[[2]uint32][]use{}
+	var order [][2]uint32
+	facets := map[[3]uint32]bool{}
+	flipped := float32(math.Cos(float64(mgl32.DegToRad(normalTolerance))))
+
+	for t, triangle := range s.Triangles {
+		corners := [3]uint32{mesh.Indices[t*3], mesh.Indices[t*3+1], mesh.Indices[t*3+2]}
+
+		a := mgl32.Vec3(triangle.Vertices[0])
+		area := mgl32.Vec3(triangle.Vertices[1]).Sub(a).
+			Cross(mgl32.Vec3(triangle.Vertices[2]).Sub(a)).Len() / 2
+
+		if area <= epsilon*epsilon || corners[0] == corners[1] ||
+			corners[1] == corners[2] || corners[2] == corners[0] {
+			report.Degenerate = append(report.Degenerate, t)
+			continue
+		}
+
+		sorted := corners
+		sort.Slice(sorted[:], func(i, j int) bool { return sorted[i] < sorted[j] })
+		if facets[sorted] {
+			report.Duplicates = append(report.Duplicates, t)
+			continue
+		}
+		facets[sorted] = true
+
+		stored := mgl32.Vec3(triangle.Normal)
+		winding := mgl32.Vec3(windingNormal(triangle))
+		if l := stored.Len(); l > 0 && stored.Mul(1/l).Dot(winding) < flipped {
+			report.FlippedNormals = append(report.FlippedNormals, t)
+		}
+
+		for i := range corners {
+			a, b := corners[i], corners[(i+1)%3]
+			key, forward := [2]uint32{a, b}, true
+			if a > b {
+				key, forward = [2]uint32{b, a}, false
+			}
+
+			if _, ok := edges[key]; !ok {
+				order = append(order, key)
+			}
+			edges[key] = append(edges[key], use{t, forward})
+		}
+	}
+
+	for _, key := range order {
+		uses := edges[key]
+		edge := MeshEdge{A: mesh.Positions[key[0]], B: mesh.Positions[key[1]]}
+		for _, u := range uses {
+			edge.Triangles = append(edge.Triangles, u.triangle)
+		}
+
+		switch {
+		case len(uses) == 1:
+			report.BoundaryEdges = append(report.BoundaryEdges, edge)
+		case len(uses) > 2:
+			report.NonManifoldEdges = append(report.NonManifoldEdges, edge)
+		case uses[0].forward == uses[1].forward:
+			report.WindingConflicts = append(report.WindingConflicts, edge)
+		}
+	}
+
+	return report
+}
+
+// Watertight
+// No holes and no edge shared by
+// more than two facets.
+func (r *MeshReport) Watertight() bool {
+	return len(r.BoundaryEdges) == 0 && len(r.NonManifoldEdges) == 0
+}
+
+// Oriented
+// Neighbouring facets agree on their
+// winding and normals agree with it.
+func (r *MeshReport) Oriented() bool {
+	return len(r.WindingConflicts) == 0 && len(r.FlippedNormals) == 0
+}
+
+// Printable
+// Watertight, oriented and free of
+// degenerate or duplicate facets.
+func (r *MeshReport) Printable() bool {
+	return r.Watertight() && r.Oriented() &&
+		len(r.Degenerate) == 0 && len(r.Duplicates) == 0
+}
+
+func (r *MeshReport) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d triangles, %d vertices", r.Triangles, r.Vertices)
+	if r.Printable() {
+		b.WriteString(", printable")
+		return b.String()
+	}
+
+	for _, defect := range []struct {
+		name  string
+		count int
+	}{
+		{"open boundary edges", len(r.BoundaryEdges)},
+		{"non-manifold edges", len(r.NonManifoldEdges)},
+		{"winding conflicts", len(r.WindingConflicts)},
+		{"degenerate facets", len(r.Degenerate)},
+		{"duplicate facets", len(r.Duplicates)},
+		{"flipped normals", len(r.FlippedNormals)},
+	} {
+		if defect.count > 0 {
+			fmt.Fprintf(&b, ", %d %s", defect.count, defect.name)
+		}
+	}
+
+	return b.String()
+}
+
+// Overlay
+// Builds highlight geometry in the interleaved
+// vertex layout: lines for the defective edges
+// and triangles for the defective facets of s,
+// which must be the solid that was analysed.
+// Degenerate facets have no area to fill, so
+// their edges are drawn instead.
+func (r *MeshReport) Overlay(s *STL) (lines, triangles []float32) {
+	line := func(a, b mgl32.Vec3, c mgl32.Vec4) {
+		for _, p := range []mgl32.Vec3{a, b} {
+			lines = append(lines,
+				p[0], p[1], p[2],
+				0, 0, 0,
+				0, 0,
+				c[0], c[1], c[2], c[3],
+			)
+		}
+	}
+
+	for _, group := range []struct {
+		edges []MeshEdge
+		color mgl32.Vec4
+	}{
+		{r.BoundaryEdges, boundaryColor},
+		{r.NonManifoldEdges, nonManifoldColor},
+		{r.WindingConflicts, windingColor},
+	} {
+		for _, edge := range group.edges {
+			line(edge.A, edge.B, group.color)
+		}
+	}
+
+	for _, t := range r.Degenerate {
+		v := s.Triangles[t].Vertices
+		for i := range v {
+			line(mgl32.Vec3(v[i]), mgl32.Vec3(v[(i+1)%3]), degenerateColor)
+		}
+	}
+
+	for _, group := range [][]int{r.Duplicates, r.FlippedNormals} {
+		for _, t := range group {
+			triangles = appendFacet(triangles, s.Triangles[t], facetColor)
+		}
+	}
+
+	return
+}
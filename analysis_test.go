@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hschendel/stl"
+)
+
+func TestAnalyze(t *testing.T) {
+	cube := prism(square(0, 10), nil, 10)
+	if report := cube.Analyze(DefaultWeldEpsilon); !report.Printable() {
+		t.Fatalf("cube: %v", report)
+	}
+
+	duplicated := prism(square(0, 10), nil, 10)
+	duplicated.Triangles = append(duplicated.Triangles, duplicated.Triangles[0])
+	report := duplicated.Analyze(DefaultWeldEpsilon)
+	if len(report.Duplicates) != 1 || !report.Watertight() || !report.Oriented() {
+		t.Errorf("duplicate facet: %v", report)
+	}
+
+	degenerate := prism(square(0, 10), nil, 10)
+	degenerate.Triangles = append(degenerate.Triangles, stl.Triangle{
+		Vertices: [3]stl.Vec3{{0, 0, 0}, {5, 0, 0}, {10, 0, 0}},
+	})
+	report = degenerate.Analyze(DefaultWeldEpsilon)
+	if len(report.Degenerate) != 1 || !report.Watertight() {
+		t.Errorf("degenerate facet: %v", report)
+	}
+
+	lines, triangles := report.Overlay(degenerate)
+	if len(lines) != 3*2*vertexFloats || len(triangles) != 0 {
+		t.Errorf("degenerate overlay: %d line and %d triangle floats", len(lines), len(triangles))
+	}
+}
@@ -20,3 +20,7 @@ func (b Buffer) BufferData(
 	b.BindBuffer()
 	gl.BufferData(b[0], size, gl.Ptr(data), usage)
 }
+
+func (b Buffer) DeleteBuffer() {
+	gl.DeleteBuffers(1, &b[1])
+}
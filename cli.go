@@ -0,0 +1,344 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// commands are run instead of the viewer
+// when named by the first argument, as in
+// `block info model.stl`.
+var commands = map[string]func(args []string) error{
+	"info":     infoCommand,
+	"split":    splitCommand,
+	"merge":    mergeCommand,
+	"slice":    sliceCommand,
+	"gcode":    gcodeCommand,
+	"estimate": estimateCommand,
+	"plate":    plateCommand,
+}
+
+// infoCommand
+// Prints the measurements of each STL.
+func infoCommand(args []string) error {
+	flags := flag.NewFlagSet("info", flag.ContinueOnError)
+	unitName := flags.String("unit", "mm", "unit of the file coordinates (mm or in)")
+	showName := flags.String("show", "", "unit to report in (default: -unit)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	unit, err := ParseUnit(*unitName)
+	if err != nil {
+		return err
+	}
+
+	show := unit
+	if *showName != "" {
+		if show, err = ParseUnit(*showName); err != nil {
+			return err
+		}
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: info [-unit mm|in] [-show mm|in] file.stl...")
+	}
+
+	for i, file := range flags.Args() {
+		s, err := LoadSTL(file)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s: %d facets\n%v\n", file, len(s.Triangles), s.Measure(unit).In(show))
+	}
+
+	return nil
+}
+
+// splitCommand
+// Writes each shell of an STL to its own
+// numbered file beside it, listing their
+// volume and bounding box.
+func splitCommand(args []string) error {
+	flags := flag.NewFlagSet("split", flag.ContinueOnError)
+	epsilon := flags.Float64("epsilon", float64(DefaultWeldEpsilon), "distance within which vertices are shared")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: split [-epsilon e] file.stl")
+	}
+
+	file := flags.Arg(0)
+	s, err := LoadSTL(file)
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	for i, shell := range s.Split(float32(*epsilon)) {
+		name := fmt.Sprintf("%s-%d.stl", base, i+1)
+		if err := shell.SaveSTL(name, STLAuto); err != nil {
+			return err
+		}
+
+		b := shell.Bounds()
+		fmt.Printf("%s: %d facets, volume %s, bounds %s .. %s\n",
+			name, len(shell.Triangles), formatNumber(float32(shell.Volume())),
+			formatVec3(b.Min), formatVec3(b.Max))
+	}
+
+	return nil
+}
+
+// mergeCommand
+// Combines STL files into one, optionally
+// spreading them along X so they do not overlap.
+func mergeCommand(args []string) error {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	out := flags.String("o", "merged.stl", "output file")
+	spacing := flags.Float64("spread", -1, "gap between parts laid out along X; negative keeps them in place")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: merge [-o out.stl] [-spread gap] file.stl...")
+	}
+
+	var solids []*STL
+	var transforms []mgl32.Mat4
+	x := float32(0)
+
+	for _, file := range flags.Args() {
+		s, err := LoadSTL(file)
+		if err != nil {
+			return err
+		}
+		solids = append(solids, s)
+
+		if *spacing >= 0 {
+			b := s.Bounds()
+			transforms = append(transforms, mgl32.Translate3D(x-b.Min[0], 0, 0))
+			x += b.Size()[0] + float32(*spacing)
+		}
+	}
+
+	merged := MergeSTL(solids, transforms...)
+	if err := merged.SaveSTL(*out, STLAuto); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d facets from %d files\n", *out, len(merged.Triangles), len(solids))
+	return nil
+}
+
+// plateCommand
+// Writes STL files and the items of 3MF
+// files as one 3MF build plate, in
+// millimetres, each item where it was.
+func plateCommand(args []string) error {
+	flags := flag.NewFlagSet("plate", flag.ContinueOnError)
+	out := flags.String("o", "plate.3mf", "output file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: plate [-o out.3mf] file.stl|file.3mf...")
+	}
+
+	plate := &ThreeMF{Unit: "millimeter"}
+	for _, file := range flags.Args() {
+		if strings.ToLower(filepath.Ext(file)) != ".3mf" {
+			s, err := LoadSTL(file)
+			if err != nil {
+				return err
+			}
+
+			item := NewThreeMFItem(s)
+			if item.Name == "" {
+				item.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			}
+			plate.Items = append(plate.Items, item)
+			continue
+		}
+
+		source, err := Open3MF(file)
+		if err != nil {
+			return err
+		}
+
+		mm := source.Millimeters()
+		for _, item := range source.Items {
+			item.Transform = mgl32.Scale3D(mm, mm, mm).Mul4(item.Transform)
+			plate.Items = append(plate.Items, item)
+		}
+	}
+
+	if err := plate.Save3MF(*out); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d items\n", *out, len(plate.Items))
+	return nil
+}
+
+// sliceCommand
+// Writes an SVG per layer of an STL.
+func sliceCommand(args []string) error {
+	flags := flag.NewFlagSet("slice", flag.ContinueOnError)
+	height := flags.Float64("layer", 0.2, "layer height")
+	out := flags.String("o", "", "output directory (default: named after the file)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: slice [-layer h] [-o dir] file.stl")
+	}
+
+	file := flags.Arg(0)
+	s, err := LoadSTL(file)
+	if err != nil {
+		return err
+	}
+
+	dir := *out
+	if dir == "" {
+		dir = strings.TrimSuffix(file, filepath.Ext(file)) + "-layers"
+	}
+
+	layers := s.Slice(float32(*height))
+	if err := SaveSVGLayers(dir, layers, s.Bounds()); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d layers\n", dir, len(layers))
+	return nil
+}
+
+// gcodeCommand
+// Slices an STL into G-code beside it.
+func gcodeCommand(args []string) error {
+	settings := DefaultGCodeSettings()
+
+	flags := flag.NewFlagSet("gcode", flag.ContinueOnError)
+	out := flags.String("o", "", "output file (default: named after the file)")
+	layer := flags.Float64("layer", float64(settings.LayerHeight), "layer height")
+	infill := flags.Float64("infill", float64(settings.InfillDensity), "infill density, 0 to 1")
+	flags.IntVar(&settings.Perimeters, "perimeters", settings.Perimeters, "perimeter count")
+	flags.IntVar(&settings.NozzleTemp, "nozzle-temp", settings.NozzleTemp, "nozzle temperature")
+	flags.IntVar(&settings.BedTemp, "bed-temp", settings.BedTemp, "bed temperature")
+	start := flags.String("start", "", "start G-code template file")
+	end := flags.String("end", "", "end G-code template file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: gcode [flags] file.stl")
+	}
+
+	settings.LayerHeight = float32(*layer)
+	settings.InfillDensity = float32(*infill)
+
+	for _, template := range []struct {
+		file string
+		dst  *string
+	}{
+		{*start, &settings.StartGCode},
+		{*end, &settings.EndGCode},
+	} {
+		if template.file == "" {
+			continue
+		}
+
+		source, err := os.ReadFile(template.file)
+		if err != nil {
+			return err
+		}
+		*template.dst = string(source)
+	}
+
+	file := flags.Arg(0)
+	s, err := LoadSTL(file)
+	if err != nil {
+		return err
+	}
+
+	path := *out
+	if path == "" {
+		path = strings.TrimSuffix(file, filepath.Ext(file)) + ".gcode"
+	}
+
+	if err := s.SaveGCode(path, settings); err != nil {
+		return err
+	}
+
+	tp, err := OpenGCode(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %s: %v\n", path, tp.Estimate(DefaultPrinterProfile()))
+	return nil
+}
+
+// estimateCommand
+// Prints the time and filament a G-code
+// file takes, optionally per layer.
+func estimateCommand(args []string) error {
+	profile := DefaultPrinterProfile()
+
+	flags := flag.NewFlagSet("estimate", flag.ContinueOnError)
+	layers := flags.Bool("layers", false, "list the time of each layer")
+	accel := flags.Float64("accel", float64(profile.PrintAcceleration), "print acceleration, mm/s²")
+	travelAccel := flags.Float64("travel-accel", float64(profile.TravelAcceleration), "travel acceleration, mm/s²")
+	deviation := flags.Float64("junction-deviation", float64(profile.JunctionDeviation), "junction deviation, mm; 0 uses -jerk")
+	jerk := flags.Float64("jerk", float64(profile.Jerk), "jerk, mm/s")
+	diameter := flags.Float64("filament", float64(profile.FilamentDiameter), "filament diameter, mm")
+	density := flags.Float64("density", float64(profile.FilamentDensity), "filament density, g/cm³")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: estimate [flags] file.gcode...")
+	}
+
+	profile.PrintAcceleration = float32(*accel)
+	profile.TravelAcceleration = float32(*travelAccel)
+	profile.JunctionDeviation = float32(*deviation)
+	profile.Jerk = float32(*jerk)
+	profile.FilamentDiameter = float32(*diameter)
+	profile.FilamentDensity = float32(*density)
+
+	for _, file := range flags.Args() {
+		tp, err := OpenGCode(file)
+		if err != nil {
+			return err
+		}
+
+		estimate := tp.Estimate(profile)
+		fmt.Printf("%s: %v\n", file, estimate)
+
+		if *layers {
+			for i, t := range estimate.Layers {
+				fmt.Printf("  layer %4d z=%7.2f %s\n", i+1, tp.Layers[i], formatDuration(t))
+			}
+		}
+	}
+
+	return nil
+}
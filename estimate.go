@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// PrinterProfile holds the motion limits used to
+// estimate print time, in mm, mm/s and mm/s².
+type PrinterProfile struct {
+	// MaxFeedRate limits each of X, Y, Z and E.
+	MaxFeedRate mgl32.Vec4
+
+	PrintAcceleration  float32
+	TravelAcceleration float32
+
+	// JunctionDeviation sets the speed kept through
+	// corners (Marlin/grbl); when zero, Jerk is
+	// the instant change of velocity allowed.
+	JunctionDeviation float32
+	Jerk              float32
+
+	FilamentDiameter float32
+
+	// FilamentDensity is in g/cm³.
+	FilamentDensity float32
+}
+
+// DefaultPrinterProfile
+// Marlin defaults for a bed slinger printing PLA.
+func DefaultPrinterProfile() PrinterProfile {
+	return PrinterProfile{
+		MaxFeedRate:        mgl32.Vec4{300, 300, 5, 25},
+		PrintAcceleration:  500,
+		TravelAcceleration: 1000,
+		JunctionDeviation:  0.013,
+		Jerk:               10,
+		FilamentDiameter:   1.75,
+		FilamentDensity:    1.24,
+	}
+}
+
+// PrintEstimate is the time and filament
+// a toolpath takes.
+type PrintEstimate struct {
+	Total  time.Duration
+	Layers []time.Duration
+
+	// Filament is the length fed in mm,
+	// net of retractions.
+	Filament float64
+	Volume   float64 // mm³
+	Mass     float64 // g
+}
+
+// Range
+// The time of layers low through high.
+func (e PrintEstimate) Range(low, high int) (t time.Duration) {
+	for i := low; i <= high && i < len(e.Layers); i++ {
+		if i >= 0 {
+			t += e.Layers[i]
+		}
+	}
+
+	return
+}
+
+// String
+// Time, filament length and mass, as
+// short as the HUD needs them.
+func (e PrintEstimate) String() string {
+	return fmt.Sprintf("%s, %.2f m, %.1f g",
+		formatDuration(e.Total), e.Filament/1000, e.Mass)
+}
+
+// plannedMove is a move as the planner sees it:
+// its length, direction and speed limits.
+type plannedMove struct {
+	length    float64
+	direction [4]float64
+	speed     float64
+	accel     float64
+	entry     float64
+	maxEntry  float64
+	layer     int
+}
+
+// Estimate
+// Plans the moves like firmware does: each is
+// limited by its feed rate and the axis maxima,
+// corners by junction deviation (or jerk), and
+// speed changes by acceleration, giving
+// trapezoidal velocity profiles.
+func (tp *Toolpath) Estimate(profile PrinterProfile) PrintEstimate {
+	estimate := PrintEstimate{Layers: make([]time.Duration, len(tp.Layers))}
+
+	moves := make([]plannedMove, 0, len(tp.Moves))
+	var filament float64
+
+	for _, m := range tp.Moves {
+		d := [4]float64{
+			float64(m.To[0] - m.From[0]),
+			float64(m.To[1] - m.From[1]),
+			float64(m.To[2] - m.From[2]),
+			float64(m.E),
+		}
+		filament += d[3]
+
+		// Firmware measures moves along XYZ, or
+		// along E when the nozzle stands still.
+		length := math.Sqrt(d[0]*d[0] + d[1]*d[1] + d[2]*d[2])
+		if length == 0 {
+			length = math.Abs(d[3])
+		}
+		if length == 0 {
+			continue
+		}
+
+		pm := plannedMove{
+			length: length,
+			speed:  float64(m.Feed) / 60,
+			accel:  float64(profile.PrintAcceleration),
+			layer:  m.Layer,
+		}
+		if m.Kind == MoveTravel {
+			pm.accel = float64(profile.TravelAcceleration)
+		}
+
+		for axis := range d {
+			pm.direction[axis] = d[axis] / length
+			if limit := float64(profile.MaxFeedRate[axis]); limit > 0 && d[axis] != 0 {
+				pm.speed = math.Min(pm.speed, limit*length/math.Abs(d[axis]))
+			}
+		}
+
+		if pm.speed <= 0 || pm.accel <= 0 {
+			continue
+		}
+
+		moves = append(moves, pm)
+	}
+
+	// The fastest each move may be entered:
+	// its own speed, that of the move before
+	// and what the corner between them allows.
+	for i := range moves {
+		if i == 0 {
+			continue
+		}
+
+		prev, cur := &moves[i-1], &moves[i]
+		cur.maxEntry = math.Min(math.Min(prev.speed, cur.speed), junctionSpeed(prev, cur, profile))
+	}
+
+	// Backward pass: leave room to decelerate
+	// into every entry, and stop at the end.
+	exit := 0.0
+	for i := len(moves) - 1; i >= 0; i-- {
+		m := &moves[i]
+		m.entry = math.Min(m.maxEntry, math.Sqrt(exit*exit+2*m.accel*m.length))
+		exit = m.entry
+	}
+
+	// Forward pass: only as fast as
+	// acceleration from standstill allows.
+	for i := range moves {
+		m := &moves[i]
+		next := 0.0
+		if i+1 < len(moves) {
+			reachable := math.Sqrt(m.entry*m.entry + 2*m.accel*m.length)
+			moves[i+1].entry = math.Min(moves[i+1].entry, reachable)
+			next = moves[i+1].entry
+		}
+
+		t := time.Duration(trapezoidTime(m.length, m.entry, m.speed, next, m.accel) * float64(time.Second))
+		estimate.Total += t
+		if m.layer < len(estimate.Layers) {
+			estimate.Layers[m.layer] += t
+		}
+	}
+
+	r := float64(profile.FilamentDiameter) / 2
+	estimate.Filament = math.Max(filament, 0)
+	estimate.Volume = estimate.Filament * math.Pi * r * r
+	estimate.Mass = estimate.Volume / 1000 * float64(profile.FilamentDensity)
+
+	return estimate
+}
+
+// junctionSpeed
+// The speed the corner between two moves can be
+// taken at without exceeding the deviation (or
+// jerk) limit.
+func junctionSpeed(prev, cur *plannedMove, profile PrinterProfile) float64 {
+	var cos, change float64
+	for axis := 0; axis < 3; axis++ {
+		cos -= prev.direction[axis] * cur.direction[axis]
+		d := cur.direction[axis] - prev.direction[axis]
+		change += d * d
+	}
+
+	if profile.JunctionDeviation <= 0 {
+		if change == 0 {
+			return math.Inf(1)
+		}
+		return float64(profile.Jerk) / math.Sqrt(change)
+	}
+
+	// Straight on, or back the way it came.
+	if cos < -0.999999 {
+		return math.Inf(1)
+	}
+	if cos > 0.999999 {
+		return 0
+	}
+
+	sinHalf := math.Sqrt((1 - cos) / 2)
+	return math.Sqrt(cur.accel * float64(profile.JunctionDeviation) * sinHalf / (1 - sinHalf))
+}
+
+// trapezoidTime
+// The time to cover length starting at entry,
+// cruising at up to speed and ending at exit,
+// changing speed at accel.
+func trapezoidTime(length, entry, speed, exit, accel float64) float64 {
+	up := (speed*speed - entry*entry) / (2 * accel)
+	down := (speed*speed - exit*exit) / (2 * accel)
+
+	if up+down <= length {
+		return (speed-entry)/accel + (speed-exit)/accel + (length-up-down)/speed
+	}
+
+	// No room to cruise: accelerate to the
+	// peak and straight back down.
+	peak := math.Sqrt((2*accel*length + entry*entry + exit*exit) / 2)
+	return math.Max(peak-entry, 0)/accel + math.Max(peak-exit, 0)/accel
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm", h, m)
+	}
+
+	return fmt.Sprintf("%dm%02ds", m, s)
+}
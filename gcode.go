@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"text/template"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// GCodeSettings configures an FDM print.
+// Lengths are millimetres, speeds mm/s,
+// temperatures °C and InfillDensity 0..1.
+type GCodeSettings struct {
+	NozzleDiameter   float32
+	FilamentDiameter float32
+	LayerHeight      float32
+
+	// ExtrusionWidth is the width of one
+	// line; zero uses 1.125 nozzles.
+	ExtrusionWidth float32
+
+	Perimeters    int
+	InfillDensity float32
+
+	// InfillAngle is the direction of the first
+	// layer's infill in degrees; each layer
+	// turns it 90° from the one below.
+	InfillAngle float32
+
+	NozzleTemp, BedTemp int
+
+	FirstLayerSpeed float32
+	PerimeterSpeed  float32
+	InfillSpeed     float32
+	TravelSpeed     float32
+
+	// Retraction pulls the filament back by
+	// RetractLength on travels longer than
+	// RetractMinTravel.
+	RetractLength    float32
+	RetractSpeed     float32
+	RetractMinTravel float32
+
+	// BedCenter is where the centre of the
+	// part's footprint is printed.
+	BedCenter mgl32.Vec2
+
+	// StartGCode and EndGCode are text/template
+	// sources executed with the settings,
+	// e.g. "M104 S{{.NozzleTemp}}".
+	StartGCode, EndGCode string
+}
+
+// DefaultGCodeSettings
+// A 0.4 mm nozzle printing PLA on a 220 mm bed.
+func DefaultGCodeSettings() GCodeSettings {
+	return GCodeSettings{
+		NozzleDiameter:   0.4,
+		FilamentDiameter: 1.75,
+		LayerHeight:      0.2,
+		Perimeters:       2,
+		InfillDensity:    0.2,
+		InfillAngle:      45,
+		NozzleTemp:       210,
+		BedTemp:          60,
+		FirstLayerSpeed:  20,
+		PerimeterSpeed:   40,
+		InfillSpeed:      60,
+		TravelSpeed:      150,
+		RetractLength:    1,
+		RetractSpeed:     35,
+		RetractMinTravel: 2,
+		BedCenter:        mgl32.Vec2{110, 110},
+		StartGCode:       defaultStartGCode,
+		EndGCode:         defaultEndGCode,
+	}
+}
+
+const defaultStartGCode = `M140 S{{.BedTemp}} ; bed temperature
+M104 S{{.NozzleTemp}} ; nozzle temperature
+G28 ; home all axes
+M190 S{{.BedTemp}} ; wait for bed
+M109 S{{.NozzleTemp}} ; wait for nozzle
+G1 Z5 F3000
+`
+
+const defaultEndGCode = `M104 S0 ; nozzle off
+M140 S0 ; bed off
+G91
+G1 Z10 F600 ; lift away from the part
+G90
+M84 ; motors off
+`
+
+// Width
+// The extrusion width in use.
+func (g GCodeSettings) Width() float32 {
+	if g.ExtrusionWidth > 0 {
+		return g.ExtrusionWidth
+	}
+
+	return g.NozzleDiameter * 1.125
+}
+
+// GCode
+// Slices the solid at the layer height and
+// writes the print to w; see WriteGCode.
+func (s *STL) GCode(w io.Writer, settings GCodeSettings) error {
+	return WriteGCode(w, s.Slice(settings.LayerHeight), s.Bounds(), settings)
+}
+
+// SaveGCode
+// Writes the print of the solid to a file.
+func (s *STL) SaveGCode(path string, settings GCodeSettings) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = s.GCode(f, settings)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// WriteGCode
+// Prints each layer as perimeters, innermost
+// first, then rectilinear infill, with relative
+// extrusion (M83). The part's footprint within
+// bounds is centred on BedCenter and its first
+// layer printed at one LayerHeight. The output
+// depends only on its inputs.
+func WriteGCode(w io.Writer, layers []Layer, bounds Bounds, settings GCodeSettings) error {
+	start, err := template.New("start").Parse(settings.StartGCode)
+	if err != nil {
+		return fmt.Errorf("start gcode: %v", err)
+	}
+
+	end, err := template.New("end").Parse(settings.EndGCode)
+	if err != nil {
+		return fmt.Errorf("end gcode: %v", err)
+	}
+
+	width := settings.Width()
+	center := bounds.Center()
+
+	g := &gcodeWriter{
+		Writer:   bufio.NewWriter(w),
+		settings: settings,
+		offset:   settings.BedCenter.Sub(mgl32.Vec2{center[0], center[1]}),
+
+		// Volume of a line per unit length over
+		// the filament's cross-section.
+		ePerMM: width * settings.LayerHeight /
+			(math.Pi * settings.FilamentDiameter * settings.FilamentDiameter / 4),
+	}
+
+	fmt.Fprintf(g, "; generated by blocked\n; layer height %.3f, width %.3f, %d perimeters, infill %.0f%%\n",
+		settings.LayerHeight, width, settings.Perimeters, settings.InfillDensity*100)
+
+	if err := start.Execute(g, settings); err != nil {
+		return fmt.Errorf("start gcode: %v", err)
+	}
+
+	fmt.Fprintf(g, "G21 ; millimetres\nG90 ; absolute positions\nM83 ; relative extrusion\n")
+
+	for i, layer := range layers {
+		z := float32(i+1) * settings.LayerHeight
+		perimeterSpeed, infillSpeed := settings.PerimeterSpeed, settings.InfillSpeed
+		if i == 0 {
+			perimeterSpeed, infillSpeed = settings.FirstLayerSpeed, settings.FirstLayerSpeed
+		}
+
+		fmt.Fprintf(g, ";LAYER:%d\n", i)
+		g.moveZ(z)
+
+		fmt.Fprintf(g, ";TYPE:PERIMETER\n")
+		for k := settings.Perimeters - 1; k >= 0; k-- {
+			for _, loop := range Offset(layer.Polygons, width*(float32(k)+0.5)) {
+				g.travel(loop[0])
+				for _, pt := range loop[1:] {
+					g.extrude(pt, perimeterSpeed)
+				}
+				g.extrude(loop[0], perimeterSpeed)
+			}
+		}
+
+		if settings.InfillDensity <= 0 {
+			continue
+		}
+
+		region := layer.Polygons
+		if settings.Perimeters > 0 {
+			region = Offset(layer.Polygons, width*float32(settings.Perimeters))
+		}
+
+		angle := settings.InfillAngle + 90*float32(i%2)
+		lines := rectilinearFill(region, width/float32(math.Min(float64(settings.InfillDensity), 1)), angle)
+		fmt.Fprintf(g, ";TYPE:INFILL\n")
+
+		for _, line := range lines {
+			// Slivers shorter than a line is wide
+			// would only dab filament.
+			if line[1].Sub(line[0]).Len() < width {
+				continue
+			}
+
+			g.travel(line[0])
+			g.extrude(line[1], infillSpeed)
+		}
+	}
+
+	g.retract()
+	fmt.Fprintf(g, ";END\n; filament used %.1f mm\n", g.filament)
+
+	if err := end.Execute(g, settings); err != nil {
+		return fmt.Errorf("end gcode: %v", err)
+	}
+
+	return g.Flush()
+}
+
+// gcodeWriter tracks the nozzle so moves are
+// only written when they go somewhere.
+type gcodeWriter struct {
+	*bufio.Writer
+	settings GCodeSettings
+	offset   mgl32.Vec2
+	ePerMM   float32
+
+	at        mgl32.Vec2
+	placed    bool
+	retracted bool
+	filament  float32
+}
+
+func (g *gcodeWriter) moveZ(z float32) {
+	fmt.Fprintf(g, "G0 Z%.3f F%.0f\n", z, g.settings.TravelSpeed*60)
+}
+
+// travel moves to p without extruding,
+// retracting first on long moves.
+func (g *gcodeWriter) travel(p mgl32.Vec2) {
+	p = p.Add(g.offset)
+	if g.placed && p.Sub(g.at).Len() < 1e-4 {
+		return
+	}
+
+	if !g.placed || p.Sub(g.at).Len() > g.settings.RetractMinTravel {
+		g.retract()
+	}
+
+	fmt.Fprintf(g, "G0 X%.3f Y%.3f F%.0f\n", p[0], p[1], g.settings.TravelSpeed*60)
+	g.at, g.placed = p, true
+}
+
+// extrude draws a line to p at speed.
+func (g *gcodeWriter) extrude(p mgl32.Vec2, speed float32) {
+	p = p.Add(g.offset)
+	length := p.Sub(g.at).Len()
+	if length < 1e-4 {
+		return
+	}
+
+	if g.retracted {
+		fmt.Fprintf(g, "G1 E%.5f F%.0f\n", g.settings.RetractLength, g.settings.RetractSpeed*60)
+		g.retracted = false
+	}
+
+	e := length * g.ePerMM
+	g.filament += e
+	fmt.Fprintf(g, "G1 X%.3f Y%.3f E%.5f F%.0f\n", p[0], p[1], e, speed*60)
+	g.at = p
+}
+
+func (g *gcodeWriter) retract() {
+	if g.retracted || g.settings.RetractLength <= 0 {
+		return
+	}
+
+	fmt.Fprintf(g, "G1 E%.5f F%.0f\n", -g.settings.RetractLength, g.settings.RetractSpeed*60)
+	g.retracted = true
+}
+
+// rectilinearFill
+// Covers the region with parallel lines spacing
+// apart at angle degrees, clipped by the even-odd
+// rule. Lines sit on a fixed grid, so layers at
+// the same angle line up, and alternate direction
+// to keep travel short.
+func rectilinearFill(region []Polygon, spacing, angle float32) (lines [][2]mgl32.Vec2) {
+	if len(region) == 0 || spacing <= 0 {
+		return nil
+	}
+
+	// Work in a frame where the lines run along X.
+	rotate := mgl32.Rotate2D(mgl32.DegToRad(-angle))
+	unrotate := mgl32.Rotate2D(mgl32.DegToRad(angle))
+
+	minY, maxY := float32(math.Inf(1)), float32(math.Inf(-1))
+	rotated := make([]Polygon, len(region))
+	for i, p := range region {
+		rotated[i] = make(Polygon, len(p))
+		for j, pt := range p {
+			r := rotate.Mul2x1(pt)
+			rotated[i][j] = r
+			minY = float32(math.Min(float64(minY), float64(r[1])))
+			maxY = float32(math.Max(float64(maxY), float64(r[1])))
+		}
+	}
+
+	first := int(math.Ceil(float64(minY / spacing)))
+	last := int(math.Floor(float64(maxY / spacing)))
+
+	for k := first; k <= last; k++ {
+		y := float32(k) * spacing
+
+		var xs []float32
+		for _, p := range rotated {
+			for i, a := range p {
+				b := p[(i+1)%len(p)]
+				if (a[1] > y) != (b[1] > y) {
+					xs = append(xs, a[0]+(y-a[1])*(b[0]-a[0])/(b[1]-a[1]))
+				}
+			}
+		}
+		sort.Slice(xs, func(i, j int) bool { return xs[i] < xs[j] })
+
+		var row [][2]mgl32.Vec2
+		for i := 0; i+1 < len(xs); i += 2 {
+			row = append(row, [2]mgl32.Vec2{
+				unrotate.Mul2x1(mgl32.Vec2{xs[i], y}),
+				unrotate.Mul2x1(mgl32.Vec2{xs[i+1], y}),
+			})
+		}
+
+		if k%2 != 0 {
+			for i, j := 0, len(row)-1; i < j; i, j = i+1, j-1 {
+				row[i], row[j] = row[j], row[i]
+			}
+			for i := range row {
+				row[i][0], row[i][1] = row[i][1], row[i][0]
+			}
+		}
+
+		lines = append(lines, row...)
+	}
+
+	return
+}
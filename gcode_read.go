@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// MoveKind classifies a toolpath move.
+type MoveKind int
+
+const (
+	MoveTravel MoveKind = iota
+	MovePerimeter
+	MoveInfill
+
+	// MoveExtrude is extrusion the file
+	// gives no ;TYPE: comment for.
+	MoveExtrude
+)
+
+func (k MoveKind) String() string {
+	switch k {
+	case MovePerimeter:
+		return "perimeter"
+	case MoveInfill:
+		return "infill"
+	case MoveExtrude:
+		return "extrude"
+	}
+
+	return "travel"
+}
+
+// ToolpathMove is one straight move of the
+// nozzle, in millimetres; arcs are split into
+// several. E is the filament fed during the
+// move and Feed the rate in mm/min.
+type ToolpathMove struct {
+	From, To mgl32.Vec3
+	E        float32
+	Feed     float32
+	Kind     MoveKind
+	Layer    int
+
+	// Line is the 1-based line of the
+	// command in the file.
+	Line int
+}
+
+// Toolpath is the parsed motion of a G-code file.
+type Toolpath struct {
+	Moves []ToolpathMove
+
+	// Layers holds the Z of each layer; a layer
+	// starts at the first extrusion at a new Z.
+	Layers []float32
+}
+
+// OpenGCode
+// Reads a G-code file; see ReadGCode.
+func OpenGCode(path string) (*Toolpath, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tp, err := ReadGCode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return tp, nil
+}
+
+// arcSegmentLength is the longest chord
+// G2/G3 arcs are split into, in mm.
+const arcSegmentLength = 0.5
+
+// ReadGCode
+// Follows G0/G1 moves and G2/G3 arcs in the XY
+// plane under G90/G91 positioning, M82/M83
+// extrusion, G92 offsets and G20/G21 units.
+// Extrusion is typed by ;TYPE: comments as
+// written by this package, Cura and PrusaSlicer.
+// Other commands are skipped.
+func ReadGCode(r io.Reader) (*Toolpath, error) {
+	p := gcodeParser{
+		tp:    &Toolpath{},
+		scale: 1,
+		feed:  1500,
+		layer: -1,
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if err := p.line(scanner.Text(), line); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return p.tp, nil
+}
+
+// gcodeParser holds the machine state; pos
+// and e are logical (after G92) positions
+// in millimetres.
+type gcodeParser struct {
+	tp *Toolpath
+
+	pos            mgl32.Vec3
+	e              float32
+	relative       bool
+	relativeE      bool
+	scale          float32
+	feed           float32
+	kind           MoveKind
+	layer          int
+	layerZ         float32
+	typedExtrusion bool
+}
+
+func (p *gcodeParser) line(text string, line int) error {
+	if i := strings.IndexByte(text, ';'); i >= 0 {
+		p.comment(strings.TrimSpace(text[i+1:]))
+		text = text[:i]
+	}
+
+	// Only moves and G92 must parse; others may
+	// carry free text (M117) or bare flags (G28 W).
+	words, err := gcodeWords(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	command := words[0]
+	strict := command.letter == 'G' && !command.flag && (command.value <= 3 || command.value == 92)
+	if err != nil {
+		if strict {
+			return err
+		}
+		return nil
+	}
+
+	for _, w := range words[1:] {
+		if strict && w.flag {
+			return fmt.Errorf("%c word needs a value", w.letter)
+		}
+	}
+
+	switch {
+	case command.letter == 'G' && (command.value == 0 || command.value == 1):
+		p.move(words[1:], line)
+	case command.letter == 'G' && (command.value == 2 || command.value == 3):
+		return p.arc(words[1:], command.value == 2, line)
+	case command.letter == 'G' && command.value == 20:
+		p.scale = 25.4
+	case command.letter == 'G' && command.value == 21:
+		p.scale = 1
+	case command.letter == 'G' && command.value == 90:
+		p.relative, p.relativeE = false, false
+	case command.letter == 'G' && command.value == 91:
+		p.relative, p.relativeE = true, true
+	case command.letter == 'G' && command.value == 92:
+		p.setPosition(words[1:])
+	case command.letter == 'M' && command.value == 82:
+		p.relativeE = false
+	case command.letter == 'M' && command.value == 83:
+		p.relativeE = true
+	}
+
+	return nil
+}
+
+// comment picks up the extrusion type.
+func (p *gcodeParser) comment(text string) {
+	if !strings.HasPrefix(strings.ToUpper(text), "TYPE:") {
+		return
+	}
+
+	kind := strings.ToUpper(text[len("TYPE:"):])
+	switch {
+	case strings.Contains(kind, "PERIMETER"), strings.Contains(kind, "WALL"):
+		p.kind = MovePerimeter
+	case strings.Contains(kind, "INFILL"), strings.Contains(kind, "FILL"), strings.Contains(kind, "SKIN"):
+		p.kind = MoveInfill
+	default:
+		p.kind = MoveExtrude
+	}
+	p.typedExtrusion = true
+}
+
+// target applies the X, Y, Z, E and F words
+// to the current state.
+func (p *gcodeParser) target(words []gcodeWord) (to mgl32.Vec3, e float32) {
+	to, e = p.pos, 0
+	for _, w := range words {
+		v := w.value * p.scale
+		switch w.letter {
+		case 'X', 'Y', 'Z':
+			axis := int(w.letter - 'X')
+			if p.relative {
+				to[axis] += v
+			} else {
+				to[axis] = v
+			}
+		case 'E':
+			if p.relativeE {
+				e = v
+			} else {
+				e = v - p.e
+			}
+		case 'F':
+			p.feed = v
+		}
+	}
+
+	return
+}
+
+func (p *gcodeParser) move(words []gcodeWord, line int) {
+	to, e := p.target(words)
+	p.segment(to, e, line)
+}
+
+// segment records a straight move and
+// advances the state to its end.
+func (p *gcodeParser) segment(to mgl32.Vec3, e float32, line int) {
+	from := p.pos
+	p.pos = to
+	p.e += e
+
+	// Moves of the filament alone (retractions)
+	// are kept as travel, as they take time.
+	xy := mgl32.Vec2{to[0] - from[0], to[1] - from[1]}
+	if xy.Len() == 0 && from[2] == to[2] && e == 0 {
+		return
+	}
+
+	kind := MoveTravel
+	if e > 0 && xy.Len() > 0 {
+		kind = MoveExtrude
+		if p.typedExtrusion {
+			kind = p.kind
+		}
+
+		if p.layer < 0 || to[2] != p.layerZ {
+			p.tp.Layers = append(p.tp.Layers, to[2])
+			p.layer = len(p.tp.Layers) - 1
+			p.layerZ = to[2]
+		}
+	}
+
+	p.tp.Moves = append(p.tp.Moves, ToolpathMove{
+		From:  from,
+		To:    to,
+		E:     e,
+		Feed:  p.feed,
+		Kind:  kind,
+		Layer: int(math.Max(float64(p.layer), 0)),
+		Line:  line,
+	})
+}
+
+// arc splits a G2 (clockwise) or G3 arc, given
+// by its centre offset I J or radius R, into
+// chords, interpolating Z and E along it.
+func (p *gcodeParser) arc(words []gcodeWord, clockwise bool, line int) error {
+	from := p.pos
+	to, e := p.target(words)
+
+	a := mgl32.Vec2{from[0], from[1]}
+	b := mgl32.Vec2{to[0], to[1]}
+
+	center := a
+	var radius float32
+	for _, w := range words {
+		switch w.letter {
+		case 'I':
+			center[0] += w.value * p.scale
+		case 'J':
+			center[1] += w.value * p.scale
+		case 'R':
+			radius = w.value * p.scale
+		}
+	}
+
+	switch {
+	case hasWord(words, 'I') || hasWord(words, 'J'):
+
+	case hasWord(words, 'R'):
+		// The centre lies on the bisector of the
+		// chord; a negative R takes the long way.
+		chord := b.Sub(a)
+		half := chord.Len() / 2
+		if half == 0 || half > float32(math.Abs(float64(radius)))+1e-4 {
+			return fmt.Errorf("arc radius %g cannot span %g", radius, 2*half)
+		}
+
+		h := float32(math.Sqrt(math.Max(float64(radius*radius-half*half), 0)))
+		normal := leftNormal(chord)
+		if clockwise == (radius < 0) {
+			center = a.Add(chord.Mul(0.5)).Add(normal.Mul(h))
+		} else {
+			center = a.Add(chord.Mul(0.5)).Sub(normal.Mul(h))
+		}
+
+	default:
+		return fmt.Errorf("arc needs I/J or R")
+	}
+
+	start := math.Atan2(float64(a[1]-center[1]), float64(a[0]-center[0]))
+	end := math.Atan2(float64(b[1]-center[1]), float64(b[0]-center[0]))
+	sweep := end - start
+	if clockwise {
+		for sweep >= 0 {
+			sweep -= 2 * math.Pi
+		}
+	} else {
+		for sweep <= 0 {
+			sweep += 2 * math.Pi
+		}
+	}
+
+	r := float64(a.Sub(center).Len())
+	steps := int(math.Max(math.Ceil(math.Abs(sweep)*r/arcSegmentLength), 1))
+
+	for i := 1; i <= steps; i++ {
+		f := float64(i) / float64(steps)
+		pt := to
+		if i < steps {
+			angle := start + sweep*f
+			pt = mgl32.Vec3{
+				center[0] + float32(r*math.Cos(angle)),
+				center[1] + float32(r*math.Sin(angle)),
+				from[2] + (to[2]-from[2])*float32(f),
+			}
+		}
+
+		p.segment(pt, e/float32(steps), line)
+	}
+
+	return nil
+}
+
+// setPosition redefines the logical
+// position of the named axes (G92).
+func (p *gcodeParser) setPosition(words []gcodeWord) {
+	if len(words) == 0 {
+		p.pos, p.e = mgl32.Vec3{}, 0
+		return
+	}
+
+	for _, w := range words {
+		switch w.letter {
+		case 'X', 'Y', 'Z':
+			p.pos[w.letter-'X'] = w.value * p.scale
+		case 'E':
+			p.e = w.value * p.scale
+		}
+	}
+}
+
+type gcodeWord struct {
+	letter byte
+	value  float32
+
+	// flag is set for a letter written
+	// without a number, as in G28 X.
+	flag bool
+}
+
+// gcodeWords
+// Splits a command line (comment removed)
+// into its letter-number words, which may
+// be written without spaces as in G1X10.
+// On an error the words before it are kept.
+func gcodeWords(text string) (words []gcodeWord, err error) {
+	text = strings.ToUpper(text)
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		if c == ' ' || c == '\t' || c == '\r' {
+			i++
+			continue
+		}
+
+		// A checksum ends the command.
+		if c == '*' {
+			break
+		}
+
+		// Skip parenthesised comments.
+		if c == '(' {
+			end := strings.IndexByte(text[i:], ')')
+			if end < 0 {
+				break
+			}
+			i += end + 1
+			continue
+		}
+
+		if c < 'A' || c > 'Z' {
+			return words, fmt.Errorf("unexpected %q", c)
+		}
+
+		j := i + 1
+		for j < len(text) && strings.IndexByte("+-.0123456789", text[j]) >= 0 {
+			j++
+		}
+
+		if j == i+1 {
+			words = append(words, gcodeWord{letter: c, flag: true})
+			i = j
+			continue
+		}
+
+		v, err := strconv.ParseFloat(text[i+1:j], 32)
+		if err != nil {
+			return words, fmt.Errorf("bad %c word %q", c, text[i+1:j])
+		}
+
+		// Line numbers (N) are dropped.
+		if c != 'N' {
+			words = append(words, gcodeWord{letter: c, value: float32(v)})
+		}
+		i = j
+	}
+
+	return
+}
+
+func hasWord(words []gcodeWord, letter byte) bool {
+	for _, w := range words {
+		if w.letter == letter {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestReadGCode(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		gcode string
+		moves int
+		to    mgl32.Vec3
+		e     float32
+	}{
+		{
+			name:  "absolute",
+			gcode: "G90\nG1 X10 Y5 Z0.2 E1 F600\n",
+			moves: 1, to: mgl32.Vec3{10, 5, 0.2}, e: 1,
+		},
+		{
+			name:  "G91 relative",
+			gcode: "G1 X10 Y10\nG91\nG1 X1 Y-2 E0.5\n",
+			moves: 2, to: mgl32.Vec3{11, 8, 0}, e: 0.5,
+		},
+		{
+			name:  "G92 offset",
+			gcode: "G1 X10 Y10 E5\nG92 X0 Y0 E0\nG1 X1 E1\n",
+			moves: 2, to: mgl32.Vec3{1, 0, 0}, e: 1,
+		},
+		{
+			name:  "M82 absolute extrusion",
+			gcode: "M82\nG1 X1 E2\nG1 X2 E3\n",
+			moves: 2, to: mgl32.Vec3{2, 0, 0}, e: 1,
+		},
+		{
+			name:  "M83 relative extrusion",
+			gcode: "M83\nG1 X1 E2\nG1 X2 E3\n",
+			moves: 2, to: mgl32.Vec3{2, 0, 0}, e: 3,
+		},
+		{
+			name:  "G20 inches",
+			gcode: "G20\nG1 X1 E0.1\n",
+			moves: 1, to: mgl32.Vec3{25.4, 0, 0}, e: 2.54,
+		},
+		{
+			name:  "I J arc",
+			gcode: "G1 X10 Y0\nG3 X-10 Y0 I-10 J0 E2\n",
+			moves: 1 + 63, to: mgl32.Vec3{-10, 0, 0}, e: 2 / 63.0,
+		},
+		{
+			name:  "R arc",
+			gcode: "G1 X10 Y0\nG2 X0 Y10 R10 E1\n",
+			moves: 1 + 32, to: mgl32.Vec3{0, 10, 0}, e: 1 / 32.0,
+		},
+		{
+			name:  "flag words",
+			gcode: "G28 W\nG29 T\nG28 X Y\nM117 Printing...\nG1 X1 E1\n",
+			moves: 1, to: mgl32.Vec3{1, 0, 0}, e: 1,
+		},
+		{
+			name:  "line numbers and checksums",
+			gcode: "M83\nN1 G1 X1 E1*57\nN2 G1X2E1 (comment)\n",
+			moves: 2, to: mgl32.Vec3{2, 0, 0}, e: 1,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tp, err := ReadGCode(strings.NewReader(test.gcode))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tp.Moves) != test.moves {
+				t.Fatalf("got %d moves, want %d", len(tp.Moves), test.moves)
+			}
+
+			last := tp.Moves[len(tp.Moves)-1]
+			if !last.To.ApproxEqualThreshold(test.to, 1e-4) {
+				t.Errorf("ends at %v, want %v", last.To, test.to)
+			}
+			if !mgl32.FloatEqualThreshold(last.E, test.e, 1e-4) {
+				t.Errorf("last move extrudes %v, want %v", last.E, test.e)
+			}
+		})
+	}
+}
+
+func TestReadGCodeErrors(t *testing.T) {
+	for _, gcode := range []string{
+		"G1 X",
+		"G1 X1.2.3",
+		"G92 E",
+		"G2 X1 Y1",
+		"G2 X30 Y0 R10",
+	} {
+		if _, err := ReadGCode(strings.NewReader(gcode)); err == nil {
+			t.Errorf("%q: no error", gcode)
+		}
+	}
+}
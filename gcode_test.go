@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hschendel/stl"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// prism extrudes a convex outer loop, and an
+// optional hole with as many points, from the
+// bed to height h. Both loops run counter-clockwise.
+func prism(outer, hole Polygon, h float32) *STL {
+	s := &STL{}
+	add := func(a, b, c mgl32.Vec3) {
+		t := stl.Triangle{Vertices: [3]stl.Vec3{stl.Vec3(a), stl.Vec3(b), stl.Vec3(c)}}
+		t.Normal = windingNormal(t)
+		s.Triangles = append(s.Triangles, t)
+	}
+
+	wall := func(p Polygon) {
+		for i, a := range p {
+			b := p[(i+1)%len(p)]
+			add(a.Vec3(0), b.Vec3(0), b.Vec3(h))
+			add(a.Vec3(0), b.Vec3(h), a.Vec3(h))
+		}
+	}
+
+	wall(outer)
+	if hole == nil {
+		for i := 1; i+1 < len(outer); i++ {
+			add(outer[0].Vec3(h), outer[i].Vec3(h), outer[i+1].Vec3(h))
+			add(outer[0].Vec3(0), outer[i+1].Vec3(0), outer[i].Vec3(0))
+		}
+		return s
+	}
+
+	reversed := append(Polygon{}, hole...)
+	reversed.Reverse()
+	wall(reversed)
+
+	for i := range outer {
+		j := (i + 1) % len(outer)
+		add(outer[i].Vec3(h), outer[j].Vec3(h), hole[j].Vec3(h))
+		add(outer[i].Vec3(h), hole[j].Vec3(h), hole[i].Vec3(h))
+		add(outer[i].Vec3(0), hole[j].Vec3(0), outer[j].Vec3(0))
+		add(outer[i].Vec3(0), hole[i].Vec3(0), hole[j].Vec3(0))
+	}
+
+	return s
+}
+
+func square(min, max float32) Polygon {
+	return Polygon{{min, min}, {max, min}, {max, max}, {min, max}}
+}
+
+func TestGCodeGolden(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		solid *STL
+	}{
+		{"cube", prism(square(0, 10), nil, 1)},
+		{"cube_hole", prism(square(0, 20), square(6, 14), 1)},
+
+		// A pillar thinner than the perimeters gets
+		// only the loops that fit inside it.
+		{"pillar", MergeSTL([]*STL{
+			prism(square(0, 10), nil, 1),
+			prism(square(12, 13), nil, 1),
+		})},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := test.solid.GCode(&buf, DefaultGCodeSettings()); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", test.name+".gcode")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output differs from %s; run with -update to rewrite it", golden)
+			}
+
+			checkFilament(t, buf.Bytes())
+			checkInside(t, buf.Bytes(), test.solid.Bounds())
+		})
+	}
+}
+
+// checkFilament compares the E of the extruding
+// moves with the total in the footer, and the net
+// E with the total less the final retraction.
+func checkFilament(t *testing.T, gcode []byte) {
+	settings := DefaultGCodeSettings()
+
+	var extruded, net, reported float64
+	scanner := bufio.NewScanner(bytes.NewReader(gcode))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if rest, ok := strings.CutPrefix(line, "; filament used "); ok {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(rest, " mm"), 64)
+			if err != nil {
+				t.Fatalf("bad footer %q", line)
+			}
+			reported = v
+			continue
+		}
+
+		if !strings.HasPrefix(line, "G1 ") {
+			continue
+		}
+
+		moves := false
+		for _, word := range strings.Fields(line)[1:] {
+			switch word[0] {
+			case 'X', 'Y':
+				moves = true
+			case 'E':
+				e, err := strconv.ParseFloat(word[1:], 64)
+				if err != nil {
+					t.Fatalf("bad extrusion %q", line)
+				}
+				net += e
+				if moves {
+					extruded += e
+				}
+			}
+		}
+	}
+
+	if reported <= 0 {
+		t.Fatal("no filament used")
+	}
+
+	if d := extruded - reported; d < -0.05 || d > 0.05 {
+		t.Errorf("extruding moves total %.3f mm, footer says %.1f mm", extruded, reported)
+	}
+
+	if d := net + float64(settings.RetractLength) - reported; d < -0.05 || d > 0.05 {
+		t.Errorf("net extrusion %.3f mm, want %.1f mm less the %.1f mm retraction", net, reported, settings.RetractLength)
+	}
+}
+
+// checkInside fails on extrusion past the
+// footprint of bounds, centred on the bed.
+func checkInside(t *testing.T, gcode []byte, bounds Bounds) {
+	settings := DefaultGCodeSettings()
+	center := bounds.Center()
+	offset := settings.BedCenter.Sub(mgl32.Vec2{center[0], center[1]})
+	min := mgl32.Vec2{bounds.Min[0], bounds.Min[1]}.Add(offset)
+	max := mgl32.Vec2{bounds.Max[0], bounds.Max[1]}.Add(offset)
+
+	scanner := bufio.NewScanner(bytes.NewReader(gcode))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "G1 X") {
+			continue
+		}
+
+		var x, y float32
+		if _, err := fmt.Sscanf(line, "G1 X%f Y%f", &x, &y); err != nil {
+			t.Fatalf("bad move %q", line)
+		}
+
+		if x < min[0] || x > max[0] || y < min[1] || y > max[1] {
+			t.Errorf("%q extrudes outside the part", line)
+		}
+	}
+}
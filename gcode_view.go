@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ToolpathColoring picks how toolpath lines are coloured.
+type ToolpathColoring int
+
+const (
+	ColorByMoveKind ToolpathColoring = iota
+	ColorByFeedRate
+)
+
+var moveKindColors = map[MoveKind]mgl32.Vec4{
+	MoveTravel:    {0.75, 0.75, 0.75, 1},
+	MovePerimeter: {0.9, 0.35, 0.1, 1},
+	MoveInfill:    {0.2, 0.6, 0.25, 1},
+	MoveExtrude:   {0.2, 0.4, 0.9, 1},
+}
+
+// ToolpathView shows a Toolpath as a line Mesh,
+// limited to the layers Low through High.
+type ToolpathView struct {
+	*Toolpath
+	Mesh *Mesh
+
+	// Estimate, if set, adds the print time
+	// of the shown layers to Status().
+	Estimate *PrintEstimate
+
+	Coloring   ToolpathColoring
+	ShowTravel bool
+	Low, High  int
+
+	// layerStarts holds the first vertex of each
+	// layer, and the vertex count at the end.
+	layerStarts []int32
+	program     Program
+}
+
+// NewToolpathView
+// Uploads the toolpath with every layer shown.
+func NewToolpathView(program Program, tp *Toolpath) *ToolpathView {
+	v := &ToolpathView{
+		Toolpath: tp,
+		High:     len(tp.Layers) - 1,
+		program:  program,
+	}
+	v.Upload()
+
+	return v
+}
+
+// Upload
+// Rebuilds the lines after a change to
+// Coloring or ShowTravel.
+func (v *ToolpathView) Upload() {
+	lines, starts := v.lines()
+	v.layerStarts = starts
+
+	if v.Mesh == nil {
+		v.Mesh = NewLineMesh(v.program, lines)
+		v.Mesh.Material = OverlayMaterial
+		v.Mesh.Toolpath = v
+	} else {
+		v.Mesh.Upload(lines)
+	}
+
+	v.SetRange(v.Low, v.High)
+}
+
+// SetRange
+// Shows layers low through high, clamped
+// to the layers of the toolpath.
+func (v *ToolpathView) SetRange(low, high int) {
+	last := len(v.Layers) - 1
+	v.Low = clampInt(low, 0, last)
+	v.High = clampInt(high, v.Low, last)
+
+	if last < 0 {
+		v.Mesh.SetRange(0, 0)
+		return
+	}
+
+	first := v.layerStarts[v.Low]
+	v.Mesh.SetRange(first, v.layerStarts[v.High+1]-first)
+}
+
+// Status
+// The shown layers, for the HUD.
+func (v *ToolpathView) Status() string {
+	if len(v.Layers) == 0 {
+		return "no layers"
+	}
+
+	status := fmt.Sprintf("layers %d-%d/%d z=%.2f-%.2f",
+		v.Low+1, v.High+1, len(v.Layers), v.Layers[v.Low], v.Layers[v.High])
+
+	if v.Estimate != nil {
+		status += fmt.Sprintf(" (%s of %v)",
+			formatDuration(v.Estimate.Range(v.Low, v.High)), *v.Estimate)
+	}
+
+	return status
+}
+
+// Bounds
+// The box of every extruding move.
+func (v *ToolpathView) Bounds() Bounds {
+	b := EmptyBounds()
+	for _, m := range v.Moves {
+		if m.Kind != MoveTravel {
+			b = b.Extend(m.From).Extend(m.To)
+		}
+	}
+
+	return b
+}
+
+// lines builds line pairs in the interleaved
+// layout, grouped by layer in file order
+// (layers are contiguous in sliced files).
+func (v *ToolpathView) lines() (lines []float32, starts []int32) {
+	minFeed, maxFeed := float32(math.Inf(1)), float32(math.Inf(-1))
+	for _, m := range v.Moves {
+		if m.Kind != MoveTravel {
+			minFeed = float32(math.Min(float64(minFeed), float64(m.Feed)))
+			maxFeed = float32(math.Max(float64(maxFeed), float64(m.Feed)))
+		}
+	}
+
+	starts = make([]int32, len(v.Layers)+1)
+	byLayer := make([][]float32, len(v.Layers))
+
+	for _, m := range v.Moves {
+		if len(v.Layers) == 0 || m.Kind == MoveTravel && !v.ShowTravel {
+			continue
+		}
+
+		c := moveKindColors[m.Kind]
+		if v.Coloring == ColorByFeedRate && m.Kind != MoveTravel {
+			c = feedColor(m.Feed, minFeed, maxFeed)
+		}
+
+		for _, p := range []mgl32.Vec3{m.From, m.To} {
+			byLayer[m.Layer] = append(byLayer[m.Layer],
+				p[0], p[1], p[2],
+				0, 0, 0,
+				0, 0,
+				c[0], c[1], c[2], c[3],
+			)
+		}
+	}
+
+	for i, layer := range byLayer {
+		starts[i] = int32(len(lines) / vertexFloats)
+		lines = append(lines, layer...)
+	}
+	starts[len(v.Layers)] = int32(len(lines) / vertexFloats)
+
+	return
+}
+
+// feedColor
+// Runs from blue at the slowest
+// feed rate to red at the fastest.
+func feedColor(feed, min, max float32) mgl32.Vec4 {
+	f := float32(0.5)
+	if max > min {
+		f = (feed - min) / (max - min)
+	}
+
+	return mgl32.Vec4{f, 0.2, 1 - f, 1}
+}
+
+func clampInt(x, min, max int) int {
+	if x > max {
+		x = max
+	}
+	if x < min {
+		x = min
+	}
+
+	return x
+}
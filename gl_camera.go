@@ -1,14 +1,29 @@
 package main
 
-import "github.com/go-gl/mathgl/mgl32"
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
 
 // Camera is a helper object
 // to interact with a camera uniform
 type Camera struct {
 	Location
 	m4 mgl32.Mat4
+
+	// The camera orbits Target at Distance,
+	// turned by Orientation (camera to world;
+	// the camera looks down its -Z).
+	Target      mgl32.Vec3
+	Distance    float32
+	Orientation mgl32.Quat
 }
 
+// minDistance keeps Dolly() short of the
+// target, where orbiting would degenerate.
+const minDistance = 1e-3
+
 // CCamera
 // Cast a Location into a Camera utility object.
 func CCamera(location Location) *Camera {
@@ -25,18 +40,187 @@ func (c *Camera) LookAtV(
 ) {
 	c.m4 = mgl32.LookAtV(eye, center, up)
 	c.UniformMatrix4fv(1, false)
+
+	// Carry on orbiting from here.
+	c.Target = center
+	c.Distance = eye.Sub(center).Len()
+	c.Orientation = mgl32.Mat4ToQuat(c.m4).Conjugate().Normalize()
+}
+
+// Eye
+// Where the camera is in the world.
+func (c *Camera) Eye() mgl32.Vec3 {
+	return c.Target.Add(c.Orientation.Rotate(mgl32.Vec3{0, 0, c.Distance}))
+}
+
+// Mat4
+// The world to camera matrix in use.
+func (c *Camera) Mat4() mgl32.Mat4 {
+	return c.m4
+}
+
+// Arcball
+// Turns the view as if dragging a ball filling
+// the viewport from (x0, y0) to (x1, y1), in
+// pixels from the top left; the scene follows
+// the cursor around Target.
+func (c *Camera) Arcball(x0, y0, x1, y1, width, height float32) {
+	p0 := arcballPoint(x0, y0, width, height)
+	p1 := arcballPoint(x1, y1, width, height)
+	if p0.ApproxEqual(p1) {
+		return
+	}
+
+	// Turning the scene by q in view space is
+	// turning the camera the other way.
+	q := mgl32.QuatBetweenVectors(p0, p1)
+	c.Orientation = c.Orientation.Mul(q.Conjugate()).Normalize()
+	c.update()
 }
 
-// @TODO not sure if I want
-// to include function to move
-// the cameras... I'm unsure how
-// that would play out sticking to
-// a move the perspective angle...
-//
-// This is something worth figuring out.
-// Especially if you consider how this
-// applies to 3D printing.
-//func (c *Camera) Pan(x, y float32)
+// arcballPoint
+// Lifts a cursor position onto the unit
+// sphere inscribed in the viewport, or its
+// rim when outside, in view space.
+func arcballPoint(x, y, width, height float32) mgl32.Vec3 {
+	r := float32(math.Min(float64(width), float64(height))) / 2
+	p := mgl32.Vec3{(x - width/2) / r, (height/2 - y) / r, 0}
+
+	if d := p[0]*p[0] + p[1]*p[1]; d <= 1 {
+		p[2] = float32(math.Sqrt(float64(1 - d)))
+	} else {
+		p = p.Normalize()
+	}
+
+	return p
+}
+
+// Pan
+// Slides the camera and Target across the
+// view plane, by world units right and up.
+func (c *Camera) Pan(right, up float32) {
+	c.Target = c.Target.
+		Add(c.Orientation.Rotate(mgl32.Vec3{1, 0, 0}).Mul(right)).
+		Add(c.Orientation.Rotate(mgl32.Vec3{0, 1, 0}).Mul(up))
+	c.update()
+}
+
+// Dolly
+// Scales the distance to Target; being a
+// factor it never reaches or passes it.
+func (c *Camera) Dolly(factor float32) {
+	c.Distance = float32(math.Max(float64(c.Distance*factor), minDistance))
+	c.update()
+}
+
+// Focus
+// Orbits point from now on, keeping the
+// direction of view and the distance.
+func (c *Camera) Focus(point mgl32.Vec3) {
+	c.Target = point
+	c.update()
+}
+
+// Fly
+// Moves the camera, and Target with it, by
+// offsets along its own axes: X right, Y up
+// and -Z forward.
+func (c *Camera) Fly(offset mgl32.Vec3) {
+	c.Target = c.Target.Add(c.Orientation.Rotate(offset))
+	c.update()
+}
+
+// maxPitch keeps Look() from tipping over
+// the world's up axis, in radians from it.
+const maxPitch = 0.01
+
+// Look
+// Turns the camera in place, yaw about the
+// world Y axis and pitch about its own X, in
+// radians; Target swings round the eye.
+func (c *Camera) Look(yaw, pitch float32) {
+	eye := c.Eye()
+	up := mgl32.Vec3{0, 1, 0}
+
+	// Stop the pitch short of looking
+	// straight up or down.
+	forward := c.Orientation.Rotate(mgl32.Vec3{0, 0, -1})
+	angle := float32(math.Acos(float64(mgl32.Clamp(forward.Dot(up), -1, 1))))
+	pitch = mgl32.Clamp(pitch, angle-math.Pi+maxPitch, angle-maxPitch)
+
+	c.Orientation = mgl32.QuatRotate(yaw, up).
+		Mul(c.Orientation).
+		Mul(mgl32.QuatRotate(pitch, mgl32.Vec3{1, 0, 0})).
+		Normalize()
+
+	c.Target = eye.Sub(c.Orientation.Rotate(mgl32.Vec3{0, 0, c.Distance}))
+	c.update()
+}
+
+// FitToBounds
+// Orbits the centre of b from just far enough
+// for its bounding sphere to fill the view of
+// p, keeping the direction of view, and clips
+// p to the sphere.
+func (c *Camera) FitToBounds(b Bounds, p *Projection) {
+	if b.Empty() {
+		return
+	}
+
+	radius := float32(math.Max(float64(b.Radius()), minDistance))
+	c.Target = b.Center()
+	c.Distance = p.FitDistance(radius)
+	c.update()
+
+	p.ClipTo(c.Distance, radius)
+}
+
+// ViewPreset names a standard direction of view.
+type ViewPreset int
+
+const (
+	ViewFront ViewPreset = iota
+	ViewBack
+	ViewLeft
+	ViewRight
+	ViewTop
+	ViewBottom
+	ViewIsometric
+)
+
+// viewPresets hold where the eye sits from the
+// target, and the up of the view. The viewer is
+// Y-up with the front towards +Z; Z-up print
+// formats are turned to match on load.
+var viewPresets = map[ViewPreset][2]mgl32.Vec3{
+	ViewFront:     {{0, 0, 1}, {0, 1, 0}},
+	ViewBack:      {{0, 0, -1}, {0, 1, 0}},
+	ViewLeft:      {{-1, 0, 0}, {0, 1, 0}},
+	ViewRight:     {{1, 0, 0}, {0, 1, 0}},
+	ViewTop:       {{0, 1, 0}, {0, 0, -1}},
+	ViewBottom:    {{0, -1, 0}, {0, 0, 1}},
+	ViewIsometric: {{1, 1, 1}, {0, 1, 0}},
+}
+
+// Preset
+// Looks at Target from a standard
+// direction, keeping the distance.
+func (c *Camera) Preset(view ViewPreset) {
+	preset := viewPresets[view]
+	c.LookAtV(
+		c.Target.Add(preset[0].Normalize().Mul(c.Distance)),
+		c.Target, preset[1],
+	)
+}
+
+// update rebuilds the matrix from the orbit.
+func (c *Camera) update() {
+	c.m4 = mgl32.Translate3D(0, 0, -c.Distance).
+		Mul4(c.Orientation.Conjugate().Mat4()).
+		Mul4(mgl32.Translate3D(-c.Target[0], -c.Target[1], -c.Target[2]))
+
+	c.UniformMatrix4fv(1, false)
+}
 
 // UniformMatrix4fv
 // Performs the same as
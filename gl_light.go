@@ -0,0 +1,50 @@
+package main
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// DirectionalLight is a helper object
+// to interact with the light uniforms
+// of the Blinn-Phong shader.
+type DirectionalLight struct {
+	// Direction the light travels in,
+	// in world space.
+	Direction mgl32.Vec3
+	Color     mgl32.Vec3
+	Ambient   mgl32.Vec3
+
+	direction, color, ambient Location
+}
+
+// CDirectionalLight
+// Cast the light uniform Locations into
+// a DirectionalLight utility object.
+func CDirectionalLight(
+	direction, color, ambient Location,
+) *DirectionalLight {
+	return &DirectionalLight{
+		direction: direction,
+		color:     color,
+		ambient:   ambient,
+	}
+}
+
+// Set
+// Stores the light parameters
+// and uploads them to the shader.
+func (l *DirectionalLight) Set(
+	direction, color, ambient mgl32.Vec3,
+) {
+	l.Direction = direction.Normalize()
+	l.Color = color
+	l.Ambient = ambient
+	l.Uniform()
+}
+
+// Uniform
+// Uploads the current light parameters,
+// e.g. after editing the fields directly.
+func (l *DirectionalLight) Uniform() {
+	l.direction.Uniform3F(l.Direction[0], l.Direction[1], l.Direction[2])
+	l.color.Uniform3F(l.Color[0], l.Color[1], l.Color[2])
+	l.ambient.Uniform3F(l.Ambient[0], l.Ambient[1], l.Ambient[2])
+}
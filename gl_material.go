@@ -0,0 +1,125 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.5-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Material describes the surface
+// of a mesh for the Blinn-Phong shader.
+type Material struct {
+	Name      string
+	Diffuse   mgl32.Vec3
+	Specular  mgl32.Vec3
+	Shininess float32
+
+	// DiffuseMap is the path of the
+	// map_Kd image, Texture its GL name
+	// once loaded with LoadTexture().
+	DiffuseMap string
+	Texture    uint32
+
+	// VertexColors shades with the per
+	// vertex colour instead of Diffuse.
+	VertexColors bool
+
+	// Unlit outputs the base colour
+	// as is, ignoring the light.
+	Unlit bool
+}
+
+// DefaultMaterial is used for meshes
+// whose format carries no material.
+var DefaultMaterial = &Material{
+	Name:      "default",
+	Diffuse:   mgl32.Vec3{0.6, 0.6, 0.65},
+	Specular:  mgl32.Vec3{0.3, 0.3, 0.3},
+	Shininess: 32,
+}
+
+// VertexColorMaterial is used for meshes
+// carrying their own colour, such as scans.
+var VertexColorMaterial = &Material{
+	Name:         "vertex color",
+	Specular:     mgl32.Vec3{0.1, 0.1, 0.1},
+	Shininess:    16,
+	VertexColors: true,
+}
+
+// OverlayMaterial is used to highlight
+// defects and other annotations.
+var OverlayMaterial = &Material{
+	Name:         "overlay",
+	VertexColors: true,
+	Unlit:        true,
+}
+
+// LoadTexture
+// Uploads the DiffuseMap image, if any,
+// through newTexture().
+func (m *Material) LoadTexture() (err error) {
+	if m.DiffuseMap == "" || m.Texture != 0 {
+		return nil
+	}
+
+	m.Texture, err = newTexture(m.DiffuseMap)
+	return
+}
+
+// MaterialUniform is a helper object
+// to interact with the material uniforms.
+type MaterialUniform struct {
+	diffuse, specular, shininess      Location
+	useTexture, useVertexColor, unlit Location
+}
+
+// CMaterialUniform
+// Cast the material uniform Locations
+// into a MaterialUniform utility object.
+func CMaterialUniform(
+	diffuse, specular, shininess,
+	useTexture, useVertexColor, unlit Location,
+) *MaterialUniform {
+	return &MaterialUniform{
+		diffuse:        diffuse,
+		specular:       specular,
+		shininess:      shininess,
+		useTexture:     useTexture,
+		useVertexColor: useVertexColor,
+		unlit:          unlit,
+	}
+}
+
+// Apply
+// Uploads the material and binds its
+// texture to TEXTURE0; nil applies
+// the DefaultMaterial.
+func (u *MaterialUniform) Apply(m *Material) {
+	if m == nil {
+		m = DefaultMaterial
+	}
+
+	u.diffuse.Uniform3F(m.Diffuse[0], m.Diffuse[1], m.Diffuse[2])
+	u.specular.Uniform3F(m.Specular[0], m.Specular[1], m.Specular[2])
+	u.shininess.Uniform1F(m.Shininess)
+
+	u.useVertexColor.Uniform1I(glBool(m.VertexColors))
+	u.unlit.Uniform1I(glBool(m.Unlit))
+
+	if m.Texture == 0 {
+		u.useTexture.Uniform1I(0)
+		return
+	}
+
+	u.useTexture.Uniform1I(1)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, m.Texture)
+}
+
+func glBool(b bool) int32 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
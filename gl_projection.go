@@ -1,18 +1,40 @@
 package main
 
-import "github.com/go-gl/mathgl/mgl32"
+import (
+	"math"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
 
 // Projection is a helper object
 // to interact with a projection uniform.
 type Projection struct {
 	Location
 	m4 mgl32.Mat4
+
+	// Orthographic views the scene without
+	// perspective, OrthoSize units from the
+	// centre to the top of the view.
+	Orthographic bool
+	OrthoSize    float32
+
+	// FieldOfView is vertical, in degrees.
+	FieldOfView       float32
+	Aspect, Near, Far float32
+
+	// ZoomLevel scales the view, smaller is closer;
+	// ZoomCenter is where it is centred, in
+	// units of the unzoomed half height.
+	ZoomLevel  float32
+	ZoomCenter mgl32.Vec2
 }
 
 // CProjection
 // Cast a Location into a Projection utility object.
 func CProjection(location Location) *Projection {
-	return &Projection{Location: location}
+	return &Projection{Location: location, ZoomLevel: 1}
 }
 
 // Perspective
@@ -25,28 +47,201 @@ func CProjection(location Location) *Projection {
 func (p *Projection) Perspective(
 	degree, aspect, near, far float32,
 ) {
-	p.m4 = mgl32.Perspective(
-		mgl32.DegToRad(degree),
-		aspect, near, far,
-	)
+	p.Orthographic = false
+	p.FieldOfView, p.Aspect = degree, aspect
+	p.Near, p.Far = near, far
+	p.update()
+}
 
-	p.UniformMatrix4fv(1, false)
+// Ortho
+// Views the box between the extents
+// without perspective, resetting the zoom.
+func (p *Projection) Ortho(
+	left, right, bottom, top, near, far float32,
+) {
+	p.Orthographic = true
+	p.OrthoSize = (top - bottom) / 2
+	p.Aspect = (right - left) / (top - bottom)
+	p.Near, p.Far = near, far
+	p.ZoomLevel = 1
+	p.ZoomCenter = mgl32.Vec2{
+		(left + right) / 2 / p.OrthoSize,
+		(bottom + top) / 2 / p.OrthoSize,
+	}
+	p.update()
+}
+
+// FollowFramebuffer
+// Keeps the viewport covering the window and
+// the aspect matching it as it is resized;
+// every other parameter, zoom included, is
+// kept. Minimised windows are ignored.
+func (p *Projection) FollowFramebuffer(w *Window) {
+	w.OnFramebufferSize(func(_ *glfw.Window, width, height int) {
+		if width <= 0 || height <= 0 {
+			return
+		}
+
+		gl.Viewport(0, 0, int32(width), int32(height))
+		p.Resize(width, height)
+	})
+}
+
+// Resize
+// Sets the aspect to that of a
+// width by height viewport.
+func (p *Projection) Resize(width, height int) {
+	p.Aspect = float32(width) / float32(height)
+	p.update()
+}
+
+// ToggleOrthographic
+// Switches between perspective and orthographic
+// views of a target distance away, keeping its
+// apparent size; returns the camera distance
+// that does so (unchanged going orthographic).
+func (p *Projection) ToggleOrthographic(distance float32) float32 {
+	slope := float32(math.Tan(float64(mgl32.DegToRad(p.FieldOfView)) / 2))
+
+	p.Orthographic = !p.Orthographic
+	if p.Orthographic {
+		p.OrthoSize = distance * slope
+	} else {
+		distance = p.OrthoSize / slope
+	}
+
+	p.update()
+	return distance
 }
 
 // Zoom
-// Performs a simple Affinine Scale
-// on the projection; this provides
-// an efficiency boost then moving the camera.
+// Scales the view about its centre;
+// below 1 zooms in.
 func (p *Projection) Zoom(scale float32) {
-	// @TODO Will the third and fourth
-	// following the decreasing linear
-	// series of changes provide a better render?
-	p.m4.Set(0, 0, p.m4.At(0, 0)*scale)
-	p.m4.Set(1, 1, p.m4.At(1, 1)*scale)
+	p.ZoomLevel *= scale
+	p.update()
+}
+
+// ZoomAt
+// Scales the view keeping the point under
+// the cursor, at ndcX, ndcY (-1 to 1, Y up),
+// in place; below 1 zooms in.
+func (p *Projection) ZoomAt(scale, ndcX, ndcY float32) {
+	cursor := p.ZoomCenter.Add(mgl32.Vec2{ndcX * p.Aspect, ndcY}.Mul(p.ZoomLevel))
+
+	p.ZoomLevel *= scale
+	p.ZoomCenter = cursor.Sub(mgl32.Vec2{ndcX * p.Aspect, ndcY}.Mul(p.ZoomLevel))
+	p.update()
+}
+
+// FitDistance
+// How far from the centre of a sphere the
+// camera must be for it to fill the view,
+// which is unzoomed and recentred. For an
+// orthographic view the sphere is fitted by
+// OrthoSize and the distance only keeps it
+// in front of the camera.
+func (p *Projection) FitDistance(radius float32) float32 {
+	p.ZoomLevel, p.ZoomCenter = 1, mgl32.Vec2{}
+
+	aspect := float32(math.Min(float64(p.Aspect), 1))
+	if p.Orthographic {
+		p.OrthoSize = radius / aspect
+		p.update()
+		return 2 * radius
+	}
+
+	// The narrower of the two field of view
+	// angles must see the sphere's tangents.
+	slope := math.Tan(float64(mgl32.DegToRad(p.FieldOfView))/2) * float64(aspect)
+	return radius / float32(math.Sin(math.Atan(slope)))
+}
+
+// ClipTo
+// Tightens the near and far planes round a
+// sphere depth in front of the camera, for
+// the most depth precision over the scene;
+// the near plane never reaches the camera.
+func (p *Projection) ClipTo(depth, radius float32) {
+	const margin, minNear = 1.01, 1e-4
+
+	far := (depth + radius) * margin
+	near := depth - radius*margin
+	if !p.Orthographic {
+		near = float32(math.Max(float64(near), float64(far)*minNear))
+	}
+
+	if far <= near {
+		return
+	}
+
+	p.Near, p.Far = near, far
+	p.update()
+}
+
+// ClipToBounds
+// Tightens the near and far planes round the
+// bounding sphere of b as camera sees it.
+func (p *Projection) ClipToBounds(camera *Camera, b Bounds) {
+	if b.Empty() {
+		return
+	}
+
+	depth := -mgl32.TransformCoordinate(b.Center(), camera.Mat4())[2]
+	p.ClipTo(depth, b.Radius())
+}
+
+// UnitsPerPixel
+// The world size of a pixel at distance from
+// the camera, with the view height pixels tall.
+func (p *Projection) UnitsPerPixel(distance float32, height int) float32 {
+	return 2 * p.halfHeight(distance) * p.ZoomLevel / float32(height)
+}
+
+// halfHeight is the unzoomed half height
+// of the view at distance.
+func (p *Projection) halfHeight(distance float32) float32 {
+	if p.Orthographic {
+		return p.OrthoSize
+	}
+
+	return distance * float32(math.Tan(float64(mgl32.DegToRad(p.FieldOfView))/2))
+}
+
+// update rebuilds the matrix as the window
+// the zoom cuts from the near plane (or the
+// orthographic view) and applies it.
+func (p *Projection) update() {
+	near := p.Near
+	if p.Orthographic {
+		near = 1
+	} else if near <= 0 {
+		// Left behind by an orthographic view,
+		// whose planes may be behind the camera.
+		near = p.Far * 1e-4
+	}
+
+	h := p.halfHeight(near)
+	left := (p.ZoomCenter[0] - p.Aspect*p.ZoomLevel) * h
+	right := (p.ZoomCenter[0] + p.Aspect*p.ZoomLevel) * h
+	bottom := (p.ZoomCenter[1] - p.ZoomLevel) * h
+	top := (p.ZoomCenter[1] + p.ZoomLevel) * h
+
+	if p.Orthographic {
+		p.m4 = mgl32.Ortho(left, right, bottom, top, p.Near, p.Far)
+	} else {
+		p.m4 = mgl32.Frustum(left, right, bottom, top, near, p.Far)
+	}
 
 	p.UniformMatrix4fv(1, false)
 }
 
+// Mat4
+// The camera to clip matrix in use.
+func (p *Projection) Mat4() mgl32.Mat4 {
+	return p.m4
+}
+
 // UniformMatrix4fv
 // Performs the same as
 // *Location.UniformMatrix4fv(); except
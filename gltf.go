@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// glTF enums used by the exporter.
+const (
+	gltfFloat        = 5126
+	gltfUnsignedInt  = 5125
+	gltfArrayBuffer  = 34962
+	gltfElementArray = 34963
+	gltfTriangles    = 4
+
+	glbMagic     = 0x46546C67 // "glTF"
+	glbChunkJSON = 0x4E4F534A // "JSON"
+	glbChunkBIN  = 0x004E4942 // "BIN\x00"
+)
+
+// GLTFScene is a set of meshes to export
+// as glTF 2.0, placed under a root node
+// carrying the viewer model matrix.
+type GLTFScene struct {
+	Model mgl32.Mat4
+	Nodes []GLTFNode
+}
+
+// GLTFNode is one mesh of the scene
+// with its own placement.
+type GLTFNode struct {
+	Name      string
+	Transform mgl32.Mat4
+	Material  *Material
+	*IndexedMesh
+}
+
+// Save
+// Writes a single file GLB when path ends in
+// .glb, otherwise glTF JSON with the binary
+// buffer in a .bin file beside it.
+func (s *GLTFScene) Save(path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if strings.EqualFold(filepath.Ext(path), ".glb") {
+		return s.WriteGLB(f)
+	}
+
+	binPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".bin"
+	bin, err := os.Create(binPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if cerr := bin.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return s.WriteGLTF(f, filepath.Base(binPath), bin)
+}
+
+// WriteGLTF
+// Writes the JSON document to w and the
+// buffer it references as binURI to bin.
+func (s *GLTFScene) WriteGLTF(w io.Writer, binURI string, bin io.Writer) error {
+	doc, data, err := s.document()
+	if err != nil {
+		return err
+	}
+
+	if len(doc.Buffers) > 0 {
+		doc.Buffers[0].URI = binURI
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err = bin.Write(data)
+	return err
+}
+
+// WriteGLB
+// Writes the scene as a binary glTF
+// with the buffer embedded.
+func (s *GLTFScene) WriteGLB(w io.Writer) error {
+	doc, data, err := s.document()
+	if err != nil {
+		return err
+	}
+
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	// Chunks are 4 byte aligned; JSON is
+	// padded with spaces, binary with zeros.
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+
+	length := 12 + 8 + len(js) + 8 + len(data)
+	header := []uint32{
+		glbMagic, 2, uint32(length),
+		uint32(len(js)), glbChunkJSON,
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(js); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, []uint32{uint32(len(data)), glbChunkBIN}); err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// document builds the glTF JSON and its
+// single binary buffer, then validates it.
+func (s *GLTFScene) document() (*gltfDocument, []byte, error) {
+	doc := &gltfDocument{
+		Asset:  gltfAsset{Version: "2.0", Generator: "Blocked"},
+		Scenes: []gltfSceneRoots{{Nodes: []int{0}}},
+		Nodes: []gltfNode{{
+			Name:   "model",
+			Matrix: gltfMatrix(s.Model),
+		}},
+	}
+
+	var data bytes.Buffer
+	materials := map[*Material]int{}
+
+	for _, node := range s.Nodes {
+		if len(node.Indices) == 0 {
+			continue
+		}
+
+		primitive := gltfPrimitive{
+			Attributes: map[string]int{},
+			Mode:       gltfTriangles,
+		}
+
+		min, max := mgl32.Vec3{}, mgl32.Vec3{}
+		for i, p := range node.Positions {
+			for a := range p {
+				if i == 0 || p[a] < min[a] {
+					min[a] = p[a]
+				}
+				if i == 0 || p[a] > max[a] {
+					max[a] = p[a]
+				}
+			}
+		}
+
+		position := doc.addAccessor(&data, node.Positions, len(node.Positions), "VEC3", gltfFloat, gltfArrayBuffer)
+		doc.Accessors[position].Min = min[:]
+		doc.Accessors[position].Max = max[:]
+		primitive.Attributes["POSITION"] = position
+
+		if len(node.Normals) == len(node.Positions) {
+			primitive.Attributes["NORMAL"] = doc.addAccessor(&data, node.Normals, len(node.Normals), "VEC3", gltfFloat, gltfArrayBuffer)
+		}
+
+		if len(node.UVs) == len(node.Positions) {
+			primitive.Attributes["TEXCOORD_0"] = doc.addAccessor(&data, node.UVs, len(node.UVs), "VEC2", gltfFloat, gltfArrayBuffer)
+		}
+
+		if len(node.Colors) == len(node.Positions) {
+			primitive.Attributes["COLOR_0"] = doc.addAccessor(&data, node.Colors, len(node.Colors), "VEC4", gltfFloat, gltfArrayBuffer)
+		}
+
+		indices := doc.addAccessor(&data, node.Indices, len(node.Indices), "SCALAR", gltfUnsignedInt, gltfElementArray)
+		primitive.Indices = &indices
+
+		if node.Material != nil {
+			index, ok := materials[node.Material]
+			if !ok {
+				index = len(doc.Materials)
+				materials[node.Material] = index
+
+				d := node.Material.Diffuse
+				doc.Materials = append(doc.Materials, gltfMaterial{
+					Name: node.Material.Name,
+					PBRMetallicRoughness: gltfPBR{
+						BaseColorFactor: []float32{d[0], d[1], d[2], 1},
+						MetallicFactor:  0,
+						RoughnessFactor: 1 - mgl32.Clamp(node.Material.Shininess/128, 0, 1),
+					},
+				})
+			}
+			primitive.Material = &index
+		}
+
+		mesh := len(doc.Meshes)
+		doc.Meshes = append(doc.Meshes, gltfMesh{
+			Name:       node.Name,
+			Primitives: []gltfPrimitive{primitive},
+		})
+
+		doc.Nodes[0].Children = append(doc.Nodes[0].Children, len(doc.Nodes))
+		doc.Nodes = append(doc.Nodes, gltfNode{
+			Name:   node.Name,
+			Mesh:   &mesh,
+			Matrix: gltfMatrix(node.Transform),
+		})
+	}
+
+	if data.Len() > 0 {
+		doc.Buffers = []gltfBuffer{{ByteLength: data.Len()}}
+	}
+
+	if err := doc.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return doc, data.Bytes(), nil
+}
+
+// addAccessor appends little endian data as a
+// new buffer view and returns its accessor.
+func (d *gltfDocument) addAccessor(
+	data *bytes.Buffer, v interface{},
+	count int, typ string,
+	componentType, target int,
+) int {
+	offset := data.Len()
+	binary.Write(data, binary.LittleEndian, v)
+
+	d.BufferViews = append(d.BufferViews, gltfBufferView{
+		ByteOffset: offset,
+		ByteLength: data.Len() - offset,
+		Target:     target,
+	})
+
+	d.Accessors = append(d.Accessors, gltfAccessor{
+		BufferView:    len(d.BufferViews) - 1,
+		ComponentType: componentType,
+		Count:         count,
+		Type:          typ,
+	})
+
+	return len(d.Accessors) - 1
+}
+
+// validate checks the fields the glTF 2.0
+// schema marks as required, and that every
+// index refers to an existing object.
+func (d *gltfDocument) validate() error {
+	if d.Asset.Version != "2.0" {
+		return errors.New("gltf: asset.version must be 2.0")
+	}
+
+	for i, b := range d.Buffers {
+		if b.ByteLength < 1 {
+			return fmt.Errorf("gltf: buffer %d: byteLength must be >= 1", i)
+		}
+	}
+
+	for i, v := range d.BufferViews {
+		if v.Buffer < 0 || v.Buffer >= len(d.Buffers) {
+			return fmt.Errorf("gltf: bufferView %d: no buffer %d", i, v.Buffer)
+		}
+
+		if v.ByteLength < 1 || v.ByteOffset+v.ByteLength > d.Buffers[v.Buffer].ByteLength {
+			return fmt.Errorf("gltf: bufferView %d: byte range out of buffer", i)
+		}
+	}
+
+	components := map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4}
+	for i, a := range d.Accessors {
+		n, ok := components[a.Type]
+		if !ok || a.Count < 1 || a.BufferView < 0 || a.BufferView >= len(d.BufferViews) {
+			return fmt.Errorf("gltf: accessor %d: invalid type, count or bufferView", i)
+		}
+
+		if a.ComponentType != gltfFloat && a.ComponentType != gltfUnsignedInt {
+			return fmt.Errorf("gltf: accessor %d: unsupported componentType %d", i, a.ComponentType)
+		}
+
+		if a.Count*n*4 > d.BufferViews[a.BufferView].ByteLength {
+			return fmt.Errorf("gltf: accessor %d: overruns its bufferView", i)
+		}
+	}
+
+	for i, m := range d.Meshes {
+		if len(m.Primitives) == 0 {
+			return fmt.Errorf("gltf: mesh %d: needs a primitive", i)
+		}
+
+		for _, p := range m.Primitives {
+			position, ok := p.Attributes["POSITION"]
+			if !ok {
+				return fmt.Errorf("gltf: mesh %d: missing POSITION", i)
+			}
+
+			// POSITION must declare its bounds.
+			if len(d.Accessors[position].Min) != 3 || len(d.Accessors[position].Max) != 3 {
+				return fmt.Errorf("gltf: mesh %d: POSITION needs min and max", i)
+			}
+
+			for name, a := range p.Attributes {
+				if a < 0 || a >= len(d.Accessors) {
+					return fmt.Errorf("gltf: mesh %d: %s has no accessor %d", i, name, a)
+				}
+			}
+
+			if p.Material != nil && *p.Material >= len(d.Materials) {
+				return fmt.Errorf("gltf: mesh %d: no material %d", i, *p.Material)
+			}
+		}
+	}
+
+	for i, n := range d.Nodes {
+		if n.Mesh != nil && *n.Mesh >= len(d.Meshes) {
+			return fmt.Errorf("gltf: node %d: no mesh %d", i, *n.Mesh)
+		}
+
+		for _, c := range n.Children {
+			if c <= 0 || c >= len(d.Nodes) {
+				return fmt.Errorf("gltf: node %d: no child %d", i, c)
+			}
+		}
+	}
+
+	return nil
+}
+
+// gltfMatrix flattens a transform in glTF's
+// column-major order, which mgl32 shares.
+// Identity, the spec default, and the unset
+// zero matrix are omitted.
+func gltfMatrix(m mgl32.Mat4) []float32 {
+	if m == mgl32.Ident4() || m == (mgl32.Mat4{}) {
+		return nil
+	}
+
+	return m[:]
+}
+
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfSceneRoots `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes,omitempty"`
+	Materials   []gltfMaterial   `json:"materials,omitempty"`
+	Accessors   []gltfAccessor   `json:"accessors,omitempty"`
+	BufferViews []gltfBufferView `json:"bufferViews,omitempty"`
+	Buffers     []gltfBuffer     `json:"buffers,omitempty"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type gltfSceneRoots struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name     string    `json:"name,omitempty"`
+	Mesh     *int      `json:"mesh,omitempty"`
+	Children []int     `json:"children,omitempty"`
+	Matrix   []float32 `json:"matrix,omitempty"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name,omitempty"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices,omitempty"`
+	Material   *int           `json:"material,omitempty"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfMaterial struct {
+	Name                 string  `json:"name,omitempty"`
+	PBRMetallicRoughness gltfPBR `json:"pbrMetallicRoughness"`
+}
+
+type gltfPBR struct {
+	BaseColorFactor []float32 `json:"baseColorFactor"`
+	MetallicFactor  float32   `json:"metallicFactor"`
+	RoughnessFactor float32   `json:"roughnessFactor"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target,omitempty"`
+}
+
+type gltfBuffer struct {
+	URI        string `json:"uri,omitempty"`
+	ByteLength int    `json:"byteLength"`
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func gltfTestScene() *GLTFScene {
+	cube := prism(square(0, 10), nil, 10).Indexed(DefaultWeldEpsilon)
+	cube.SmoothNormals(DefaultCreaseAngle)
+
+	// A second mesh sharing the material.
+	triangle := &IndexedMesh{
+		Positions: []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		Indices:   []uint32{0, 1, 2},
+	}
+
+	red := &Material{Name: "red", Diffuse: mgl32.Vec3{1, 0, 0}, Shininess: 32}
+	return &GLTFScene{
+		Model: mgl32.Ident4(),
+		Nodes: []GLTFNode{
+			{Name: "cube", Transform: mgl32.Translate3D(1, 2, 3), Material: red, IndexedMesh: cube},
+			{Name: "triangle", Transform: mgl32.Ident4(), Material: red, IndexedMesh: triangle},
+		},
+	}
+}
+
+func TestWriteGLB(t *testing.T) {
+	scene := gltfTestScene()
+
+	var buf bytes.Buffer
+	if err := scene.WriteGLB(&buf); err != nil {
+		t.Fatal(err)
+	}
+	glb := buf.Bytes()
+
+	le := binary.LittleEndian
+	if le.Uint32(glb) != glbMagic || le.Uint32(glb[4:]) != 2 {
+		t.Fatalf("bad header % x", glb[:8])
+	}
+	if int(le.Uint32(glb[8:])) != len(glb) {
+		t.Fatalf("header length %d, file is %d bytes", le.Uint32(glb[8:]), len(glb))
+	}
+
+	jsonLength := int(le.Uint32(glb[12:]))
+	if le.Uint32(glb[16:]) != glbChunkJSON || jsonLength%4 != 0 {
+		t.Fatalf("JSON chunk type %x, length %d", le.Uint32(glb[16:]), jsonLength)
+	}
+
+	var doc gltfDocument
+	if err := json.Unmarshal(glb[20:20+jsonLength], &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	bin := glb[20+jsonLength:]
+	binLength := int(le.Uint32(bin))
+	if le.Uint32(bin[4:]) != glbChunkBIN || binLength%4 != 0 || 8+binLength != len(bin) {
+		t.Fatalf("BIN chunk type %x, length %d of %d", le.Uint32(bin[4:]), binLength, len(bin)-8)
+	}
+	data := bin[8:]
+
+	if len(doc.Buffers) != 1 || doc.Buffers[0].URI != "" || doc.Buffers[0].ByteLength > binLength {
+		t.Fatalf("buffers %+v", doc.Buffers)
+	}
+	if err := doc.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Meshes) != 2 || len(doc.Materials) != 1 {
+		t.Fatalf("%d meshes and %d materials, want 2 and 1", len(doc.Meshes), len(doc.Materials))
+	}
+
+	// The cube positions read back from the buffer.
+	cube := scene.Nodes[0]
+	accessor := doc.Accessors[doc.Meshes[0].Primitives[0].Attributes["POSITION"]]
+	view := doc.BufferViews[accessor.BufferView]
+	positions := make([]mgl32.Vec3, accessor.Count)
+	if err := binary.Read(bytes.NewReader(data[view.ByteOffset:view.ByteOffset+view.ByteLength]), le, positions); err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range cube.Positions {
+		if positions[i] != p {
+			t.Fatalf("position %d: %v, want %v", i, positions[i], p)
+		}
+	}
+
+	if got := mgl32.Mat4(*(*[16]float32)(doc.Nodes[1].Matrix)); got != cube.Transform {
+		t.Errorf("cube matrix %v, want %v", got, cube.Transform)
+	}
+	if doc.Nodes[2].Matrix != nil {
+		t.Errorf("identity written as %v", doc.Nodes[2].Matrix)
+	}
+}
+
+func TestSaveGLTF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scene.gltf")
+	if err := gltfTestScene().Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	js, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc gltfDocument
+	if err := json.Unmarshal(js, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	bin, err := os.ReadFile(strings.TrimSuffix(path, ".gltf") + ".bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Buffers[0].URI != "scene.bin" || doc.Buffers[0].ByteLength != len(bin) {
+		t.Errorf("buffer %+v, .bin is %d bytes", doc.Buffers[0], len(bin))
+	}
+}
+
+func TestGLTFValidate(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		spoil func(d *gltfDocument)
+	}{
+		{"version", func(d *gltfDocument) { d.Asset.Version = "1.0" }},
+		{"empty buffer", func(d *gltfDocument) { d.Buffers[0].ByteLength = 0 }},
+		{"view past buffer", func(d *gltfDocument) { d.BufferViews[0].ByteLength = d.Buffers[0].ByteLength + 1 }},
+		{"accessor type", func(d *gltfDocument) { d.Accessors[0].Type = "MAT5" }},
+		{"accessor overrun", func(d *gltfDocument) { d.Accessors[0].Count *= 2 }},
+		{"component type", func(d *gltfDocument) { d.Accessors[0].ComponentType = 5120 }},
+		{"missing position", func(d *gltfDocument) { delete(d.Meshes[0].Primitives[0].Attributes, "POSITION") }},
+		{"position bounds", func(d *gltfDocument) { d.Accessors[0].Min = nil }},
+		{"material", func(d *gltfDocument) { *d.Meshes[0].Primitives[0].Material = 5 }},
+		{"child", func(d *gltfDocument) { d.Nodes[0].Children = append(d.Nodes[0].Children, 9) }},
+	} {
+		doc, _, err := gltfTestScene().document()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		test.spoil(doc)
+		if err := doc.validate(); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// DefaultWeldEpsilon is the distance under
+// which two STL vertices are treated as the
+// same corner when building an IndexedMesh.
+const DefaultWeldEpsilon float32 = 1e-5
+
+// DefaultCreaseAngle is the angle in degrees
+// between two faces above which SmoothNormals
+// keeps a hard edge instead of blending.
+const DefaultCreaseAngle float32 = 30
+
+// Interleaved vertex layout shared by every
+// mesh upload: X,Y,Z, NX,NY,NZ, U,V, R,G,B,A.
+const (
+	vertexFloats         = 12
+	vertexStride         = vertexFloats * 4
+	vertexPositionOffset = 0
+	vertexNormalOffset   = 3 * 4
+	vertexTexCoordOffset = 6 * 4
+	vertexColorOffset    = 8 * 4
+)
+
+// white is the vertex colour of
+// meshes that carry none.
+var white = mgl32.Vec4{1, 1, 1, 1}
+
+// IndexedMesh is a triangle mesh where
+// shared corners are stored once and
+// referenced by the index list.
+//
+// Normals, UVs and Colors are optional and,
+// when present, run parallel to Positions.
+type IndexedMesh struct {
+	Positions []mgl32.Vec3
+	Normals   []mgl32.Vec3
+	UVs       []mgl32.Vec2
+	Colors    []mgl32.Vec4
+	Indices   []uint32
+}
+
+// Indexed
+// Welds the triangle soup of the solid into an
+// IndexedMesh; vertices closer than epsilon are
+// merged. An epsilon <= 0 only merges exact matches.
+func (s *STL) Indexed(epsilon float32) *IndexedMesh {
+	welder := newVertexWelder(epsilon, len(s.Triangles)/2)
+	mesh := &IndexedMesh{
+		Indices: make([]uint32, 0, len(s.Triangles)*3),
+	}
+
+	for _, triangle := range s.Triangles {
+		for _, vertex := range triangle.Vertices {
+			mesh.Indices = append(mesh.Indices, welder.weld(mgl32.Vec3(vertex)))
+		}
+	}
+
+	mesh.Positions = welder.positions
+	return mesh
+}
+
+// DedupRatio
+// Reports how many triangle corners share
+// each stored vertex on average; a soup
+// with no shared corners reports 1.
+func (m *IndexedMesh) DedupRatio() float32 {
+	if len(m.Positions) == 0 {
+		return 1
+	}
+
+	return float32(len(m.Indices)) / float32(len(m.Positions))
+}
+
+// Vertices
+// Interleaves positions, normals, texture
+// coordinates and colours (see vertexStride)
+// ready for an ARRAY_BUFFER upload; missing
+// streams are zeroed, missing colours white.
+func (m *IndexedMesh) Vertices() []float32 {
+	vertices := make([]float32, 0, len(m.Positions)*vertexFloats)
+	for i, p := range m.Positions {
+		var n mgl32.Vec3
+		var uv mgl32.Vec2
+		c := white
+
+		if i < len(m.Normals) {
+			n = m.Normals[i]
+		}
+
+		if i < len(m.UVs) {
+			uv = m.UVs[i]
+		}
+
+		if i < len(m.Colors) {
+			c = m.Colors[i]
+		}
+
+		vertices = append(vertices,
+			p[0], p[1], p[2],
+			n[0], n[1], n[2],
+			uv[0], uv[1],
+			c[0], c[1], c[2], c[3],
+		)
+	}
+
+	return vertices
+}
+
+// SmoothNormals
+// Computes per-vertex normals by averaging the
+// area weighted normals of the faces around each
+// vertex. Faces meeting at more than creaseDegrees
+// are not blended; the shared vertex is split so
+// each side keeps its own normal.
+func (m *IndexedMesh) SmoothNormals(creaseDegrees float32) {
+	faces := len(m.Indices) / 3
+	faceNormals := make([]mgl32.Vec3, faces)
+	incident := make([][]uint32, len(m.Positions))
+
+	for f := 0; f < faces; f++ {
+		a := m.Positions[m.Indices[f*3]]
+		b := m.Positions[m.Indices[f*3+1]]
+		c := m.Positions[m.Indices[f*3+2]]
+
+		// The cross product length is twice the
+		// area, which gives the area weighting.
+		faceNormals[f] = b.Sub(a).Cross(c.Sub(a))
+
+		for _, index := range m.Indices[f*3 : f*3+3] {
+			incident[index] = append(incident[index], uint32(f))
+		}
+	}
+
+	crease := float32(math.Cos(float64(mgl32.DegToRad(creaseDegrees))))
+
+	type corner struct {
+		position uint32
+		normal   mgl32.Vec3
+	}
+
+	split := make(map[corner]uint32, len(m.Positions))
+	mesh := IndexedMesh{
+		Indices: make([]uint32, len(m.Indices)),
+	}
+
+	for i, index := range m.Indices {
+		own := unitOrZero(faceNormals[i/3])
+
+		var normal mgl32.Vec3
+		for _, f := range incident[index] {
+			if unitOrZero(faceNormals[f]).Dot(own) >= crease {
+				normal = normal.Add(faceNormals[f])
+			}
+		}
+		normal = unitOrZero(normal)
+
+		key := corner{index, normal}
+		vertex, ok := split[key]
+		if !ok {
+			vertex = uint32(len(mesh.Positions))
+			split[key] = vertex
+			mesh.Positions = append(mesh.Positions, m.Positions[index])
+			mesh.Normals = append(mesh.Normals, normal)
+			if int(index) < len(m.UVs) {
+				mesh.UVs = append(mesh.UVs, m.UVs[index])
+			}
+			if int(index) < len(m.Colors) {
+				mesh.Colors = append(mesh.Colors, m.Colors[index])
+			}
+		}
+
+		mesh.Indices[i] = vertex
+	}
+
+	*m = mesh
+}
+
+// WithTriangleColors
+// Copies the mesh with one colour per triangle
+// carried as vertex colours; vertices shared by
+// differently coloured triangles are split.
+func (m *IndexedMesh) WithTriangleColors(colors []mgl32.Vec4) *IndexedMesh {
+	type corner struct {
+		position uint32
+		color    mgl32.Vec4
+	}
+
+	split := make(map[corner]uint32, len(m.Positions))
+	mesh := &IndexedMesh{
+		Indices: make([]uint32, len(m.Indices)),
+	}
+
+	for i, index := range m.Indices {
+		color := white
+		if i/3 < len(colors) {
+			color = colors[i/3]
+		}
+
+		key := corner{index, color}
+		vertex, ok := split[key]
+		if !ok {
+			vertex = uint32(len(mesh.Positions))
+			split[key] = vertex
+			mesh.Positions = append(mesh.Positions, m.Positions[index])
+			mesh.Colors = append(mesh.Colors, color)
+			if int(index) < len(m.Normals) {
+				mesh.Normals = append(mesh.Normals, m.Normals[index])
+			}
+			if int(index) < len(m.UVs) {
+				mesh.UVs = append(mesh.UVs, m.UVs[index])
+			}
+		}
+
+		mesh.Indices[i] = vertex
+	}
+
+	return mesh
+}
+
+func unitOrZero(v mgl32.Vec3) mgl32.Vec3 {
+	if l := v.Len(); l > 0 {
+		return v.Mul(1 / l)
+	}
+
+	return v
+}
+
+// vertexWelder deduplicates positions through a
+// spatial hash with cells epsilon wide, so only
+// the 27 neighbouring cells need to be searched.
+type vertexWelder struct {
+	epsilon   float32
+	cells     map[[3]int64][]uint32
+	positions []mgl32.Vec3
+}
+
+func newVertexWelder(epsilon float32, capacity int) *vertexWelder {
+	return &vertexWelder{
+		epsilon:   epsilon,
+		cells:     make(map[[3]int64][]uint32, capacity),
+		positions: make([]mgl32.Vec3, 0, capacity),
+	}
+}
+
+func (w *vertexWelder) cell(v mgl32.Vec3) (key [3]int64) {
+	for i, f := range v {
+		if w.epsilon <= 0 {
+			// -0 and 0 are the same corner
+			if f == 0 {
+				f = 0
+			}
+			key[i] = int64(math.Float32bits(f))
+		} else {
+			key[i] = int64(math.Floor(float64(f / w.epsilon)))
+		}
+	}
+
+	return
+}
+
+func (w *vertexWelder) weld(v mgl32.Vec3) uint32 {
+	key := w.cell(v)
+
+	if w.epsilon <= 0 {
+		if found, ok := w.cells[key]; ok {
+			return found[0]
+		}
+	} else {
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for dz := int64(-1); dz <= 1; dz++ {
+					near := [3]int64{key[0] + dx, key[1] + dy, key[2] + dz}
+					for _, index := range w.cells[near] {
+						if w.positions[index].Sub(v).Len() <= w.epsilon {
+							return index
+						}
+					}
+				}
+			}
+		}
+	}
+
+	index := uint32(len(w.positions))
+	w.positions = append(w.positions, v)
+	w.cells[key] = append(w.cells[key], index)
+	return index
+}
@@ -7,16 +7,22 @@ import "github.com/go-gl/glfw/v3.1/glfw"
 
 type Key int
 type ModifierKey int
-type Keyboard [glfw.KeyLast]bool
+type Keyboard [glfw.KeyLast + 1]bool
 
 //Keyboard structure manipulation
-func (k Keyboard) glfwKeyCallback(
+//(the pointer receiver keeps the state;
+//KeyUnknown is ignored)
+func (k *Keyboard) glfwKeyCallback(
 	window *glfw.Window,
 	key glfw.Key,
 	scancode int,
 	action glfw.Action,
 	mods glfw.ModifierKey,
 ) {
+	if key < 0 || int(key) >= len(k) {
+		return
+	}
+
 	if action == glfw.Press {
 		k[key] = true
 	} else if action == glfw.Release {
@@ -25,7 +31,11 @@ func (k Keyboard) glfwKeyCallback(
 }
 
 //Test if a key is down
-func (k Keyboard) IsDown(key Key) bool {
+func (k *Keyboard) IsDown(key Key) bool {
+	if key < 0 || int(key) >= len(k) {
+		return false
+	}
+
 	return k[glfw.Key(int(key))]
 }
 
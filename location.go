@@ -41,3 +41,11 @@ func (l Location) VertexAttribPointer(
 		gl.PtrOffset(pointer),
 	)
 }
+
+func (l Location) Uniform1F(v0 float32) {
+	gl.Uniform1f(int32(l), v0)
+}
+
+func (l Location) Uniform3F(v0, v1, v2 float32) {
+	gl.Uniform3f(int32(l), v0, v1, v2)
+}
@@ -6,11 +6,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/draw"
+	_ "image/jpeg"
 	_ "image/png"
+	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
 
 	"github.com/go-gl/gl/v4.5-core/gl"
 	"github.com/go-gl/glfw/v3.1/glfw"
@@ -21,6 +27,16 @@ const windowWidth = 800
 const windowHeight = 600
 
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	window := NewWindow("Block", windowWidth, windowHeight, false, true, true)
 
 	window.OnRun(func(_ *glfw.Window) {
@@ -41,21 +57,8 @@ func main() {
 			0.1, 100.0,
 		)
 
-		// Projection zoom on scroll
-		window.OnScroll(func(
-			_ *glfw.Window,
-			xoff, yoff float64,
-		) {
-			var scale float32
-
-			if yoff > 0 {
-				scale = 0.95
-			} else {
-				scale = 1.05
-			}
-
-			projection.Zoom(scale)
-		})
+		// Projection follow window resizes
+		projection.FollowFramebuffer(window)
 
 		camera := CCamera(
 			program.GetUniformLocation("camera"),
@@ -67,13 +70,195 @@ func main() {
 			mgl32.Vec3{0, 1, 0},
 		)
 
+		// Camera dolly on scroll; orthographic
+		// views zoom about the cursor instead.
+		window.OnScroll(func(
+			w *glfw.Window,
+			xoff, yoff float64,
+		) {
+			scale := float32(0.9)
+			if yoff < 0 {
+				scale = 1 / scale
+			}
+
+			if !projection.Orthographic {
+				camera.Dolly(scale)
+				return
+			}
+
+			x, y := w.GetCursorPos()
+			width, height := w.GetSize()
+			projection.ZoomAt(scale,
+				float32(2*x/float64(width)-1),
+				float32(1-2*y/float64(height)),
+			)
+		})
+
+		// Keypad 5 toggles an orthographic view;
+		// 1, 3 and 7 look from the front, right and
+		// top, with Ctrl from the back, left and
+		// bottom, and 0 isometric.
+		window.OnKey(func(
+			_ *glfw.Window, key glfw.Key, _ int,
+			action glfw.Action, mods glfw.ModifierKey,
+		) {
+			if action != glfw.Press {
+				return
+			}
+
+			opposite := mods&glfw.ModControl != 0
+			pick := func(view, other ViewPreset) ViewPreset {
+				if opposite {
+					return other
+				}
+				return view
+			}
+
+			switch Key(key) {
+			case KeyKP5:
+				camera.Dolly(projection.ToggleOrthographic(camera.Distance) / camera.Distance)
+			case KeyKP1:
+				camera.Preset(pick(ViewFront, ViewBack))
+			case KeyKP3:
+				camera.Preset(pick(ViewRight, ViewLeft))
+			case KeyKP7:
+				camera.Preset(pick(ViewTop, ViewBottom))
+			case KeyKP0:
+				camera.Preset(ViewIsometric)
+			}
+		})
+
+		// F switches between orbiting and flying:
+		// the mouse looks around with the cursor
+		// captured, W A S D move, Q and E sink and
+		// rise and Shift speeds it all up.
+		const (
+			flySpeed     = 0.5
+			flyBoost     = 4.0
+			lookPerPixel = 0.003
+		)
+		flying := false
+		window.OnKey(func(
+			w *glfw.Window, key glfw.Key, _ int,
+			action glfw.Action, mods glfw.ModifierKey,
+		) {
+			if action != glfw.Press || mods != 0 {
+				return
+			}
+
+			switch {
+			case key == glfw.KeyF:
+				flying = !flying
+			case key == glfw.KeyEscape && flying:
+				flying = false
+			default:
+				return
+			}
+
+			if flying {
+				w.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+				window.SetStatus("camera", "flying")
+			} else {
+				w.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+				window.SetStatus("camera", "")
+			}
+		})
+
+		window.OnUpdate(func(dt float64) {
+			if !flying {
+				return
+			}
+
+			var move mgl32.Vec3
+			for _, binding := range []struct {
+				key    Key
+				offset mgl32.Vec3
+			}{
+				{KeyW, mgl32.Vec3{0, 0, -1}},
+				{KeyS, mgl32.Vec3{0, 0, 1}},
+				{KeyA, mgl32.Vec3{-1, 0, 0}},
+				{KeyD, mgl32.Vec3{1, 0, 0}},
+				{KeyQ, mgl32.Vec3{0, -1, 0}},
+				{KeyE, mgl32.Vec3{0, 1, 0}},
+			} {
+				if window.Keyboard.IsDown(binding.key) {
+					move = move.Add(binding.offset)
+				}
+			}
+
+			if move.Len() == 0 {
+				return
+			}
+
+			// Speeds are in orbit distances per second,
+			// which fitting scales to the scene.
+			speed := camera.Distance * float32(flySpeed*dt)
+			if window.Keyboard.IsDown(KeyLeftShift) || window.Keyboard.IsDown(KeyRightShift) {
+				speed *= flyBoost
+			}
+			camera.Fly(move.Normalize().Mul(speed))
+		})
+
+		// Otherwise left drag orbits the camera
+		// around its target and middle drag pans
+		// it, keeping the point under the cursor.
+		var cursorX, cursorY float64
+		window.OnCursorPos(func(w *glfw.Window, xpos, ypos float64) {
+			width, height := w.GetSize()
+
+			switch {
+			case flying:
+				camera.Look(
+					float32(cursorX-xpos)*lookPerPixel,
+					float32(cursorY-ypos)*lookPerPixel,
+				)
+
+			case window.Mouse.IsDown(MouseButtonLeft):
+				camera.Arcball(
+					float32(cursorX), float32(cursorY),
+					float32(xpos), float32(ypos),
+					float32(width), float32(height),
+				)
+
+			case window.Mouse.IsDown(MouseButtonMiddle):
+				perPixel := projection.UnitsPerPixel(camera.Distance, height)
+				camera.Pan(
+					float32(cursorX-xpos)*perPixel,
+					float32(ypos-cursorY)*perPixel,
+				)
+			}
+
+			cursorX, cursorY = xpos, ypos
+		})
+
+		// Double click refocuses the orbit on the
+		// surface under the cursor; the depth is
+		// read back once the next frame is drawn.
+		const doubleClickTime = 0.3
+		var lastClick float64
+		var focusAt *[2]float64
+		window.OnMouseButton(func(
+			_ *glfw.Window, button glfw.MouseButton,
+			action glfw.Action, _ glfw.ModifierKey,
+		) {
+			if button != glfw.MouseButtonLeft || action != glfw.Press {
+				return
+			}
+
+			now := glfw.GetTime()
+			if now-lastClick < doubleClickTime {
+				focusAt = &[2]float64{cursorX, cursorY}
+			}
+			lastClick = now
+		})
+
 		model := mgl32.Ident4()
 		var modelYaw, modelPitch float32
 		modelUniform := program.GetUniformLocation("model")
 		modelUniform.UniformMatrix4fv(1, false, &model[0])
 
 		window.OnCursorPos(func(_ *glfw.Window, xpos, ypos float64) {
-			if window.Mouse.IsDown(MouseButtonRight) {
+			if window.Mouse.IsDown(MouseButtonRight) && !flying {
 				midx := float64(window.Width >> 1)
 				midy := float64(window.Height >> 1)
 
@@ -94,33 +279,344 @@ func main() {
 		textureUniform := program.GetUniformLocation("tex")
 		textureUniform.Uniform1I(0)
 
-		program.BindFragDataLocation(0, "outputColor")
+		material := CMaterialUniform(
+			program.GetUniformLocation("diffuseColor"),
+			program.GetUniformLocation("specularColor"),
+			program.GetUniformLocation("shininess"),
+			program.GetUniformLocation("useTexture"),
+			program.GetUniformLocation("useVertexColor"),
+			program.GetUniformLocation("unlit"),
+		)
+
+		light := CDirectionalLight(
+			program.GetUniformLocation("lightDirection"),
+			program.GetUniformLocation("lightColor"),
+			program.GetUniformLocation("ambientColor"),
+		)
+
+		light.Set(
+			mgl32.Vec3{-1, -2, -1},
+			mgl32.Vec3{1, 1, 1},
+			mgl32.Vec3{0.2, 0.2, 0.2},
+		)
 
-		// Load the texture
-		// texture, err := newTexture("square.png")
-		// if err != nil {
-		// 	log.Fatalln(err)
-		// }
+		program.BindFragDataLocation(0, "outputColor")
 
 		// Configure the vertex data
-		vao := GenVertexArray()
-		vao.BindVertexArray()
+		files := os.Args[1:]
+		if len(files) == 0 {
+			files = []string{"model.stl"}
+		}
 
-		stl := OpenSTL("model.stl")
-		stl.Scale(0.25)
-		vertices := stl.Vertices()
-		fmt.Println(vertices[0:20])
+		var meshes []*Mesh
+		open := func(files ...string) {
+			for _, file := range files {
+				loaded, err := loadMeshes(program, file)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
 
-		vbo := GenBuffer(gl.ARRAY_BUFFER)
-		vbo.BufferData(len(vertices)*4, vertices, gl.STATIC_DRAW)
+				for _, mesh := range loaded {
+					mesh.Name = filepath.Base(file)
+					mesh.Path = file
+				}
+				meshes = append(meshes, loaded...)
+			}
+		}
 
-		vertAttrib := program.GetAttribLocation("vert")
-		vertAttrib.EnableVertexAttribArray()
-		vertAttrib.VertexAttribPointer(3, gl.FLOAT, false, 5*4, 0)
+		// The HUD shows the size of the last
+		// loaded solid; U switches mm and inch.
+		hudUnit := Millimeter
+		showMeasurements := func() {
+			for i := len(meshes) - 1; i >= 0; i-- {
+				if meshes[i].STL != nil {
+					m := meshes[i].STL.Measure(Millimeter).In(hudUnit)
+					window.SetStatus("size", meshes[i].Name+" "+m.Summary())
+					return
+				}
+			}
+		}
 
-		texCoordAttrib := program.GetAttribLocation("vertTexCoord")
-		texCoordAttrib.EnableVertexAttribArray()
-		texCoordAttrib.VertexAttribPointer(2, gl.FLOAT, false, 5*4, 3*4)
+		window.OnKey(func(
+			_ *glfw.Window, key glfw.Key, _ int,
+			action glfw.Action, mods glfw.ModifierKey,
+		) {
+			if key == glfw.KeyU && action == glfw.Press && mods == 0 {
+				hudUnit = (hudUnit + 1) % 2
+				showMeasurements()
+			}
+		})
+
+		// The box of everything drawn, for
+		// framing and clipping the view.
+		sceneBounds := func() Bounds {
+			b := EmptyBounds()
+			for _, mesh := range meshes {
+				if !mesh.Overlay {
+					b = b.Union(mesh.Bounds.Transform(model.Mul4(mesh.Transform)))
+				}
+			}
+			return b
+		}
+
+		// Keypad . frames the whole scene, as
+		// happens whenever files are loaded.
+		window.OnKey(func(
+			_ *glfw.Window, key glfw.Key, _ int,
+			action glfw.Action, _ glfw.ModifierKey,
+		) {
+			if Key(key) == KeyKPDecimal && action == glfw.Press {
+				camera.FitToBounds(sceneBounds(), projection)
+			}
+		})
+
+		open(files...)
+		camera.FitToBounds(sceneBounds(), projection)
+		showMeasurements()
+
+		// V toggles the mesh validation overlays.
+		showOverlays := true
+		window.OnKey(func(
+			_ *glfw.Window, key glfw.Key, _ int,
+			action glfw.Action, mods glfw.ModifierKey,
+		) {
+			if key == glfw.KeyV && action == glfw.Press && mods == 0 {
+				showOverlays = !showOverlays
+			}
+		})
+
+		// L previews the layers of the last loaded
+		// solid; Page Up and Page Down step through
+		// them, holding Shift for ten at a time.
+		const previewLayerHeight = 0.2
+		var (
+			layers       []Layer
+			layerMesh    *Mesh
+			layerIndex   int
+			layerPreview bool
+		)
+
+		showLayer := func() {
+			if layerMesh != nil {
+				layerMesh.Delete()
+				layerMesh = nil
+			}
+
+			if !layerPreview || len(layers) == 0 {
+				window.SetStatus("layer", "")
+				return
+			}
+
+			for i := len(meshes) - 1; i >= 0; i-- {
+				if meshes[i].STL != nil {
+					layerMesh = NewLineMesh(program, layers[layerIndex].Lines())
+					layerMesh.Transform = meshes[i].Transform
+					layerMesh.Material = OverlayMaterial
+					break
+				}
+			}
+
+			window.SetStatus("layer", fmt.Sprintf("layer %d/%d z=%.2f",
+				layerIndex+1, len(layers), layers[layerIndex].Z))
+		}
+
+		window.OnKey(func(
+			_ *glfw.Window, key glfw.Key, _ int,
+			action glfw.Action, mods glfw.ModifierKey,
+		) {
+			if action == glfw.Release {
+				return
+			}
+
+			step := 1
+			if mods&glfw.ModShift != 0 {
+				step = 10
+			}
+
+			switch key {
+			case glfw.KeyL:
+				if action != glfw.Press || mods != 0 {
+					return
+				}
+
+				layerPreview = !layerPreview
+				layers = nil
+				if layerPreview {
+					for i := len(meshes) - 1; i >= 0; i-- {
+						if meshes[i].STL != nil {
+							layers = meshes[i].STL.Slice(previewLayerHeight)
+							break
+						}
+					}
+					layerIndex = len(layers) / 2
+				}
+
+			case glfw.KeyPageUp:
+				layerIndex += step
+
+			case glfw.KeyPageDown:
+				layerIndex -= step
+
+			default:
+				return
+			}
+
+			if layerIndex >= len(layers) {
+				layerIndex = len(layers) - 1
+			}
+			if layerIndex < 0 {
+				layerIndex = 0
+			}
+			showLayer()
+		})
+
+		// G-code toolpaths show a range of layers:
+		// holding Up or Down moves the top layer,
+		// with Shift the bottom one; Home shows them
+		// all. C colours by move or feed rate and
+		// T shows travel moves.
+		toolpath := func() *ToolpathView {
+			for i := len(meshes) - 1; i >= 0; i-- {
+				if meshes[i].Toolpath != nil {
+					return meshes[i].Toolpath
+				}
+			}
+			return nil
+		}
+
+		showToolpath := func() {
+			if view := toolpath(); view != nil {
+				window.SetStatus("toolpath", view.Status())
+			}
+		}
+		showToolpath()
+
+		const layersPerSecond = 15
+		var layerStep float64
+		window.OnUpdate(func(dt float64) {
+			view := toolpath()
+			if view == nil {
+				return
+			}
+
+			direction := 0
+			if window.Keyboard.IsDown(KeyUp) {
+				direction++
+			}
+			if window.Keyboard.IsDown(KeyDown) {
+				direction--
+			}
+
+			if direction == 0 {
+				layerStep = 0
+				return
+			}
+
+			layerStep += float64(direction) * layersPerSecond * dt
+			step := int(layerStep)
+			if step == 0 {
+				return
+			}
+			layerStep -= float64(step)
+
+			if window.Keyboard.IsDown(KeyLeftShift) || window.Keyboard.IsDown(KeyRightShift) {
+				view.SetRange(view.Low+step, view.High)
+			} else {
+				view.SetRange(view.Low, view.High+step)
+			}
+			showToolpath()
+		})
+
+		window.OnKey(func(
+			_ *glfw.Window, key glfw.Key, _ int,
+			action glfw.Action, mods glfw.ModifierKey,
+		) {
+			view := toolpath()
+			if view == nil || action != glfw.Press || mods != 0 {
+				return
+			}
+
+			switch key {
+			case glfw.KeyHome:
+				view.SetRange(0, len(view.Layers)-1)
+			case glfw.KeyC:
+				view.Coloring = (view.Coloring + 1) % 2
+				view.Upload()
+			case glfw.KeyT:
+				view.ShowTravel = !view.ShowTravel
+				view.Upload()
+			default:
+				return
+			}
+			showToolpath()
+		})
+
+		// Dropped files are added to the scene; a
+		// bad file is reported and skipped.
+		window.OnDrop(func(_ *glfw.Window, names []string) {
+			open(names...)
+			camera.FitToBounds(sceneBounds(), projection)
+			showMeasurements()
+			showToolpath()
+		})
+
+		// Ctrl+E exports what is on screen
+		// for sharing with web viewers.
+		window.OnKey(func(
+			_ *glfw.Window, key glfw.Key, _ int,
+			action glfw.Action, mods glfw.ModifierKey,
+		) {
+			if key != glfw.KeyE || action != glfw.Press || mods&glfw.ModControl == 0 {
+				return
+			}
+
+			scene := GLTFScene{Model: model}
+			for _, mesh := range meshes {
+				if mesh.Indexed == nil || mesh.Overlay {
+					continue
+				}
+
+				scene.Nodes = append(scene.Nodes, GLTFNode{
+					Name:        mesh.Name,
+					Transform:   mesh.Transform,
+					Material:    mesh.Material,
+					IndexedMesh: mesh.Indexed,
+				})
+			}
+
+			if err := scene.Save("scene.glb"); err != nil {
+				log.Println("export:", err)
+				return
+			}
+			fmt.Println("exported scene.glb")
+		})
+
+		// Ctrl+G writes G-code for the last loaded
+		// solid beside its file, as the print
+		// would come out with the default settings.
+		window.OnKey(func(
+			_ *glfw.Window, key glfw.Key, _ int,
+			action glfw.Action, mods glfw.ModifierKey,
+		) {
+			if key != glfw.KeyG || action != glfw.Press || mods&glfw.ModControl == 0 {
+				return
+			}
+
+			for i := len(meshes) - 1; i >= 0; i-- {
+				if meshes[i].STL == nil {
+					continue
+				}
+
+				path := strings.TrimSuffix(meshes[i].Path, filepath.Ext(meshes[i].Path)) + ".gcode"
+				if err := meshes[i].STL.SaveGCode(path, DefaultGCodeSettings()); err != nil {
+					log.Println("gcode:", err)
+					return
+				}
+				fmt.Println("wrote", path)
+				return
+			}
+		})
 
 		// Configure global settings
 		gl.Enable(gl.DEPTH_TEST)
@@ -144,20 +640,277 @@ func main() {
 
 			// Render
 			program.Use()
+			projection.ClipToBounds(camera, sceneBounds())
 			//modelUniform.UniformMatrix4fv(1, false, &model[0])
 
-			vao.BindVertexArray()
+			for _, mesh := range meshes {
+				if mesh.Overlay {
+					continue
+				}
+
+				placed := model.Mul4(mesh.Transform)
+				modelUniform.UniformMatrix4fv(1, false, &placed[0])
+				material.Apply(mesh.Material)
+
+				// A mirroring transform turns the
+				// winding, and so the culled side.
+				if placed.Det() < 0 {
+					gl.FrontFace(gl.CW)
+				}
+				mesh.Draw()
+				gl.FrontFace(gl.CCW)
+			}
+
+			if focusAt != nil {
+				if point, ok := surfacePoint(window, camera, projection, focusAt[0], focusAt[1]); ok {
+					camera.Focus(point)
+				}
+				focusAt = nil
+			}
+
+			if layerMesh != nil {
+				gl.Disable(gl.DEPTH_TEST)
+				placed := model.Mul4(layerMesh.Transform)
+				modelUniform.UniformMatrix4fv(1, false, &placed[0])
+				material.Apply(layerMesh.Material)
+				layerMesh.Draw()
+				gl.Enable(gl.DEPTH_TEST)
+			}
 
-			//gl.ActiveTexture(gl.TEXTURE0)
-			//gl.BindTexture(gl.TEXTURE_2D, texture)
+			if !showOverlays {
+				return
+			}
 
-			gl.DrawArrays(gl.TRIANGLES, 0, 6*2*3)
+			gl.Disable(gl.DEPTH_TEST)
+			for _, mesh := range meshes {
+				if !mesh.Overlay {
+					continue
+				}
+
+				placed := model.Mul4(mesh.Transform)
+				modelUniform.UniformMatrix4fv(1, false, &placed[0])
+				material.Apply(mesh.Material)
+				mesh.Draw()
+			}
+			gl.Enable(gl.DEPTH_TEST)
 		})
 	})
 
 	window.Run()
 }
 
+// surfacePoint
+// Reads the depth under the cursor back from the
+// frame just drawn and unprojects it into the
+// world; false where nothing was drawn.
+func surfacePoint(
+	window *Window, camera *Camera, projection *Projection,
+	x, y float64,
+) (mgl32.Vec3, bool) {
+	// Cursor positions are in screen coordinates,
+	// which differ from pixels on HiDPI screens.
+	width, height := window.GetSize()
+	px := int(x * float64(window.Width) / float64(width))
+	py := window.Height - 1 - int(y*float64(window.Height)/float64(height))
+	if px < 0 || py < 0 || px >= window.Width || py >= window.Height {
+		return mgl32.Vec3{}, false
+	}
+
+	var depth float32
+	gl.ReadPixels(int32(px), int32(py), 1, 1, gl.DEPTH_COMPONENT, gl.FLOAT, gl.Ptr(&depth))
+	if depth >= 1 {
+		return mgl32.Vec3{}, false
+	}
+
+	point, err := mgl32.UnProject(
+		mgl32.Vec3{float32(px) + 0.5, float32(py) + 0.5, depth},
+		camera.Mat4(), projection.Mat4(),
+		0, 0, window.Width, window.Height,
+	)
+
+	return point, err == nil
+}
+
+// printToView turns the Z-up coordinates of
+// printing formats (STL, 3MF, G-code) into the
+// viewer's Y-up, so the view presets see the
+// part the way it sits on the bed.
+var printToView = mgl32.HomogRotate3DX(mgl32.DegToRad(-90))
+
+// loadMeshes
+// Opens a model by its extension and
+// uploads every mesh it contains.
+func loadMeshes(program Program, file string) ([]*Mesh, error) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".obj":
+		obj, err := OpenOBJ(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var meshes []*Mesh
+		for _, object := range obj.Objects {
+			if object.Material != nil {
+				if err := object.Material.LoadTexture(); err != nil {
+					return nil, err
+				}
+			}
+
+			mesh := NewMesh(program, object.IndexedMesh)
+			mesh.Material = object.Material
+			meshes = append(meshes, mesh)
+		}
+
+		return meshes, nil
+
+	case ".3mf":
+		plate, err := Open3MF(file)
+		if err != nil {
+			return nil, err
+		}
+
+		scale := mgl32.Scale3D(plate.Millimeters(), plate.Millimeters(), plate.Millimeters())
+
+		var meshes []*Mesh
+		for _, item := range plate.Items {
+			mesh := NewMesh(program, item.Renderable())
+			mesh.Transform = printToView.Mul4(scale).Mul4(item.Transform)
+			mesh.Material = item.Material
+			if item.TriangleColors != nil {
+				mesh.Material = VertexColorMaterial
+			}
+			meshes = append(meshes, mesh)
+		}
+
+		return meshes, nil
+
+	case ".gcode", ".gco", ".g":
+		tp, err := OpenGCode(file)
+		if err != nil {
+			return nil, err
+		}
+
+		view := NewToolpathView(program, tp)
+		estimate := tp.Estimate(DefaultPrinterProfile())
+		view.Estimate = &estimate
+		fmt.Printf("%s: %v\n", file, estimate)
+		center := view.Bounds().Center()
+		view.Mesh.Transform = printToView.Mul4(mgl32.Translate3D(-center[0], -center[1], -center[2]))
+
+		return []*Mesh{view.Mesh}, nil
+
+	case ".ply":
+		indexed, err := OpenPLY(file)
+		if err != nil {
+			return nil, err
+		}
+
+		mesh := NewMesh(program, indexed)
+		if indexed.Colors != nil {
+			mesh.Material = VertexColorMaterial
+		}
+
+		return []*Mesh{mesh}, nil
+
+	default:
+		if info, err := os.Stat(file); err == nil && info.Size() > largeSTLSize {
+			return streamMesh(program, file)
+		}
+
+		stl, err := LoadSTL(file)
+		if err != nil {
+			return nil, err
+		}
+
+		indexed := stl.Indexed(DefaultWeldEpsilon)
+		fmt.Printf("welded %d corners into %d vertices (%.2fx)\n",
+			len(indexed.Indices), len(indexed.Positions), indexed.DedupRatio())
+
+		colors := stl.FacetColors()
+		if colors != nil {
+			indexed = indexed.WithTriangleColors(colors)
+		}
+		indexed.SmoothNormals(DefaultCreaseAngle)
+
+		mesh := NewMesh(program, indexed)
+		mesh.STL = stl
+		if material, ok := stl.HeaderMaterial(); ok {
+			mesh.Material = material
+		}
+		if colors != nil {
+			mesh.Material = VertexColorMaterial
+		}
+
+		report := stl.Analyze(DefaultWeldEpsilon)
+		fmt.Printf("%s: %v\n", file, report)
+
+		// Parts are centred in the view without
+		// moving their coordinates.
+		place := printToView.Mul4(stl.Edit().CenterOnOrigin().Matrix)
+
+		meshes := append([]*Mesh{mesh}, overlayMeshes(program, stl, report)...)
+		for _, mesh := range meshes {
+			mesh.Transform = place
+		}
+
+		return meshes, nil
+	}
+}
+
+// overlayMeshes
+// Uploads the defect highlights of a report.
+func overlayMeshes(program Program, stl *STL, report *MeshReport) (meshes []*Mesh) {
+	lines, triangles := report.Overlay(stl)
+
+	if len(lines) > 0 {
+		meshes = append(meshes, NewLineMesh(program, lines))
+	}
+
+	if len(triangles) > 0 {
+		meshes = append(meshes, NewArrayMesh(program, triangles))
+	}
+
+	for _, mesh := range meshes {
+		mesh.Material = OverlayMaterial
+		mesh.Overlay = true
+	}
+
+	return
+}
+
+// largeSTLSize is the file size above which STL
+// files are streamed as a flat triangle soup
+// rather than welded and smoothed.
+const largeSTLSize = 64 << 20
+
+// streamMesh
+// Streams a large STL into a Mesh, printing the
+// progress; Ctrl+C cancels the load.
+func streamMesh(program Program, file string) ([]*Mesh, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	vertices, err := StreamSTLFile(ctx, file, func(p STLProgress) {
+		fmt.Printf("\rloading %s: %3.0f%%", file, p.Fraction()*100)
+	})
+	fmt.Println()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Centred like the STLs loaded whole,
+	// from the bounds of the vertex buffer.
+	mesh := NewArrayMesh(program, vertices)
+	mesh.Transform = printToView
+	if !mesh.Bounds.Empty() {
+		center := mesh.Bounds.Center()
+		mesh.Transform = printToView.Mul4(mgl32.Translate3D(-center[0], -center[1], -center[2]))
+	}
+
+	return []*Mesh{mesh}, nil
+}
+
 func newTexture(file string) (uint32, error) {
 	imgFile, err := os.Open(file)
 	if err != nil {
@@ -196,6 +949,8 @@ func newTexture(file string) (uint32, error) {
 	return texture, nil
 }
 
+// Blinn-Phong shading lit by a single directional
+// light; lighting is evaluated in view space.
 var vertexShader = `
 #version 330
 
@@ -204,27 +959,80 @@ uniform mat4 camera;
 uniform mat4 model;
 
 in vec3 vert;
+in vec3 vertNormal;
 in vec2 vertTexCoord;
+in vec4 vertColor;
 
+out vec3 fragPosition;
+out vec3 fragNormal;
 out vec2 fragTexCoord;
+out vec4 fragColor;
 
 void main() {
+    mat4 modelView = camera * model;
+    vec4 position = modelView * vec4(vert, 1);
+
+    fragPosition = position.xyz;
+    fragNormal = mat3(transpose(inverse(modelView))) * vertNormal;
     fragTexCoord = vertTexCoord;
-    gl_Position = projection * camera * model * vec4(vert, 1);
+    fragColor = vertColor;
+    gl_Position = projection * position;
 }
 ` + "\x00"
 
 var fragmentShader = `
 #version 330
 
+uniform mat4 camera;
 uniform sampler2D tex;
+uniform bool useTexture;
+uniform bool useVertexColor;
+uniform bool unlit;
 
+uniform vec3 lightDirection;
+uniform vec3 lightColor;
+uniform vec3 ambientColor;
+
+uniform vec3 diffuseColor;
+uniform vec3 specularColor;
+uniform float shininess;
+
+in vec3 fragPosition;
+in vec3 fragNormal;
 in vec2 fragTexCoord;
+in vec4 fragColor;
 
 out vec4 outputColor;
 
 void main() {
-    outputColor = texture(tex, fragTexCoord);
+    vec3 base = diffuseColor;
+    if (useVertexColor) {
+        base = fragColor.rgb;
+    }
+    if (useTexture) {
+        base *= texture(tex, fragTexCoord).rgb;
+    }
+
+    if (unlit) {
+        outputColor = vec4(base, 1);
+        return;
+    }
+
+    vec3 normal = normalize(fragNormal);
+    vec3 toLight = normalize(-mat3(camera) * lightDirection);
+    vec3 toEye = normalize(-fragPosition);
+    vec3 halfway = normalize(toLight + toEye);
+
+    float diffuse = max(dot(normal, toLight), 0.0);
+    float specular = 0.0;
+    if (diffuse > 0.0) {
+        specular = pow(max(dot(normal, halfway), 0.0), shininess);
+    }
+
+    vec3 color = ambientColor * base +
+        lightColor * (diffuse * base + specular * specularColor);
+
+    outputColor = vec4(color, 1);
 }
 ` + "\x00"
 
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/hschendel/stl"
+)
+
+// Unit is the length unit model
+// coordinates are expressed in.
+type Unit int
+
+const (
+	Millimeter Unit = iota
+	Inch
+)
+
+// ParseUnit
+// Accepts the usual spellings of mm and inch.
+func ParseUnit(name string) (Unit, error) {
+	switch strings.ToLower(name) {
+	case "mm", "millimeter", "millimetre", "millimeters", "millimetres":
+		return Millimeter, nil
+	case "in", "inch", "inches", `"`:
+		return Inch, nil
+	}
+
+	return Millimeter, fmt.Errorf("unknown unit %q, want mm or in", name)
+}
+
+// Millimeters
+// The length of one unit in millimetres.
+func (u Unit) Millimeters() float64 {
+	if u == Inch {
+		return 25.4
+	}
+
+	return 1
+}
+
+func (u Unit) String() string {
+	if u == Inch {
+		return "in"
+	}
+
+	return "mm"
+}
+
+// Bounds is an axis-aligned box;
+// Min > Max when it is empty.
+type Bounds struct {
+	Min, Max mgl32.Vec3
+}
+
+// EmptyBounds
+// A box that any point extends.
+func EmptyBounds() Bounds {
+	inf := float32(math.Inf(1))
+	return Bounds{
+		Min: mgl32.Vec3{inf, inf, inf},
+		Max: mgl32.Vec3{-inf, -inf, -inf},
+	}
+}
+
+// Empty
+// Whether no point was added.
+func (b Bounds) Empty() bool {
+	return b.Min[0] > b.Max[0]
+}
+
+// Extend
+// Grows the box to contain p.
+func (b Bounds) Extend(p mgl32.Vec3) Bounds {
+	for i := range p {
+		b.Min[i] = float32(math.Min(float64(b.Min[i]), float64(p[i])))
+		b.Max[i] = float32(math.Max(float64(b.Max[i]), float64(p[i])))
+	}
+
+	return b
+}
+
+// Union
+// The box containing both boxes.
+func (b Bounds) Union(o Bounds) Bounds {
+	if o.Empty() {
+		return b
+	}
+
+	return b.Extend(o.Min).Extend(o.Max)
+}
+
+// Transform
+// The box containing the eight
+// corners of b transformed by m.
+func (b Bounds) Transform(m mgl32.Mat4) Bounds {
+	if b.Empty() {
+		return b
+	}
+
+	out := EmptyBounds()
+	for i := 0; i < 8; i++ {
+		corner := b.Min
+		for axis := 0; axis < 3; axis++ {
+			if i&(1<<uint(axis)) != 0 {
+				corner[axis] = b.Max[axis]
+			}
+		}
+		out = out.Extend(mgl32.TransformCoordinate(corner, m))
+	}
+
+	return out
+}
+
+func (b Bounds) Size() mgl32.Vec3 {
+	if b.Empty() {
+		return mgl32.Vec3{}
+	}
+
+	return b.Max.Sub(b.Min)
+}
+
+func (b Bounds) Center() mgl32.Vec3 {
+	if b.Empty() {
+		return mgl32.Vec3{}
+	}
+
+	return b.Min.Add(b.Max).Mul(0.5)
+}
+
+// Radius
+// The radius of the sphere around
+// Center() enclosing the box.
+func (b Bounds) Radius() float32 {
+	return b.Size().Len() / 2
+}
+
+// OrientedBounds is a box aligned to the
+// principal axes of a surface; Axes are
+// unit length and sorted by extent.
+type OrientedBounds struct {
+	Center      mgl32.Vec3
+	Axes        [3]mgl32.Vec3
+	HalfExtents mgl32.Vec3
+}
+
+func (o OrientedBounds) Size() mgl32.Vec3 {
+	return o.HalfExtents.Mul(2)
+}
+
+func (o OrientedBounds) Volume() float64 {
+	s := o.Size()
+	return float64(s[0]) * float64(s[1]) * float64(s[2])
+}
+
+// Bounds
+// The axis-aligned box of every vertex.
+func (s *STL) Bounds() Bounds {
+	b := EmptyBounds()
+	for _, triangle := range s.Triangles {
+		for _, v := range triangle.Vertices {
+			b = b.Extend(mgl32.Vec3(v))
+		}
+	}
+
+	return b
+}
+
+// Volume
+// The signed volume enclosed by the facets;
+// negative when the solid is inside out and
+// meaningless when it is not watertight.
+func (s *STL) Volume() (volume float64) {
+	for _, triangle := range s.Triangles {
+		volume += facetVolume(triangle)
+	}
+
+	return
+}
+
+// SurfaceArea
+// The summed area of every facet.
+func (s *STL) SurfaceArea() (area float64) {
+	for _, triangle := range s.Triangles {
+		a, b, c := facetCorners(triangle)
+		area += b.Sub(a).Cross(c.Sub(a)).Len() / 2
+	}
+
+	return
+}
+
+// Centroid
+// The centre of mass of the solid assuming
+// uniform density; open surfaces enclosing
+// no volume use their area centroid.
+func (s *STL) Centroid() mgl32.Vec3 {
+	var solid, surface [3]float64
+	var volume, area float64
+
+	for _, triangle := range s.Triangles {
+		a, b, c := facetCorners(triangle)
+		v := facetVolume(triangle)
+		w := b.Sub(a).Cross(c.Sub(a)).Len() / 2
+
+		// The tetrahedron to the origin has its
+		// centroid at a quarter of the corner sum.
+		for i := range solid {
+			sum := a[i] + b[i] + c[i]
+			solid[i] += v * sum / 4
+			surface[i] += w * sum / 3
+		}
+		volume += v
+		area += w
+	}
+
+	centroid, weight := solid, volume
+	if math.Abs(volume) < 1e-12 {
+		centroid, weight = surface, area
+	}
+	if weight == 0 {
+		return mgl32.Vec3{}
+	}
+
+	return mgl32.Vec3{
+		float32(centroid[0] / weight),
+		float32(centroid[1] / weight),
+		float32(centroid[2] / weight),
+	}
+}
+
+// OrientedBounds
+// Fits a box to the principal axes of the
+// area weighted facet covariance, falling back
+// to Bounds() when that is the smaller box.
+func (s *STL) OrientedBounds() OrientedBounds {
+	var mean [3]float64
+	var area float64
+
+	for _, triangle := range s.Triangles {
+		a, b, c := facetCorners(triangle)
+		w := b.Sub(a).Cross(c.Sub(a)).Len() / 2
+		for i := range mean {
+			mean[i] += w * (a[i] + b[i] + c[i]) / 3
+		}
+		area += w
+	}
+
+	b := s.Bounds()
+	aligned := OrientedBounds{
+		Center:      b.Center(),
+		Axes:        [3]mgl32.Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+		HalfExtents: b.Size().Mul(0.5),
+	}
+
+	if area == 0 {
+		return aligned
+	}
+
+	for i := range mean {
+		mean[i] /= area
+	}
+
+	// Second moment of each facet about the
+	// mean, exact for a uniform triangle.
+	var cov [3][3]float64
+	for _, triangle := range s.Triangles {
+		a, b, c := facetCorners(triangle)
+		w := b.Sub(a).Cross(c.Sub(a)).Len() / 2
+
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				ai, bi, ci := a[i]-mean[i], b[i]-mean[i], c[i]-mean[i]
+				aj, bj, cj := a[j]-mean[j], b[j]-mean[j], c[j]-mean[j]
+				sum := (ai+bi+ci)*(aj+bj+cj) + ai*aj + bi*bj + ci*cj
+				cov[i][j] += w * sum / 12
+			}
+		}
+	}
+
+	axes := symmetricEigenvectors(cov)
+
+	var lo, hi [3]float64
+	for i := range lo {
+		lo[i], hi[i] = math.Inf(1), math.Inf(-1)
+	}
+
+	for _, triangle := range s.Triangles {
+		for _, v := range triangle.Vertices {
+			for i, axis := range axes {
+				d := float64(mgl32.Vec3(v).Dot(axis))
+				lo[i], hi[i] = math.Min(lo[i], d), math.Max(hi[i], d)
+			}
+		}
+	}
+
+	o := OrientedBounds{Axes: axes}
+	for i, axis := range axes {
+		o.Center = o.Center.Add(axis.Mul(float32((lo[i] + hi[i]) / 2)))
+		o.HalfExtents[i] = float32((hi[i] - lo[i]) / 2)
+	}
+
+	// Principal axes are a heuristic; keep
+	// the axis-aligned box when it is smaller.
+	if aligned.Volume() <= o.Volume() {
+		o = aligned
+	}
+
+	// Order the axes longest first.
+	for i := 0; i < 2; i++ {
+		for j := i + 1; j < 3; j++ {
+			if o.HalfExtents[j] > o.HalfExtents[i] {
+				o.HalfExtents[i], o.HalfExtents[j] = o.HalfExtents[j], o.HalfExtents[i]
+				o.Axes[i], o.Axes[j] = o.Axes[j], o.Axes[i]
+			}
+		}
+	}
+
+	return o
+}
+
+// Measurements gathers the dimensions of
+// a solid in the unit of its coordinates.
+type Measurements struct {
+	Unit     Unit
+	Bounds   Bounds
+	Oriented OrientedBounds
+	Volume   float64
+	Area     float64
+	Centroid mgl32.Vec3
+}
+
+// Measure
+// Takes every measurement of the solid, whose
+// coordinates are in unit (STL has none of
+// its own; millimetres are the convention).
+func (s *STL) Measure(unit Unit) Measurements {
+	return Measurements{
+		Unit:     unit,
+		Bounds:   s.Bounds(),
+		Oriented: s.OrientedBounds(),
+		Volume:   s.Volume(),
+		Area:     s.SurfaceArea(),
+		Centroid: s.Centroid(),
+	}
+}
+
+// In
+// Converts the measurements to another unit;
+// areas and volumes scale by the square and
+// cube of the length factor.
+func (m Measurements) In(unit Unit) Measurements {
+	if unit == m.Unit {
+		return m
+	}
+
+	k := m.Unit.Millimeters() / unit.Millimeters()
+	f := float32(k)
+
+	m.Unit = unit
+	m.Bounds.Min, m.Bounds.Max = m.Bounds.Min.Mul(f), m.Bounds.Max.Mul(f)
+	m.Oriented.Center = m.Oriented.Center.Mul(f)
+	m.Oriented.HalfExtents = m.Oriented.HalfExtents.Mul(f)
+	m.Area *= k * k
+	m.Volume *= k * k * k
+	m.Centroid = m.Centroid.Mul(f)
+
+	return m
+}
+
+// Summary
+// One line of the part size and volume,
+// short enough for the window title.
+func (m Measurements) Summary() string {
+	s := m.Bounds.Size()
+	return fmt.Sprintf("%s x %s x %s %s, %s %s³",
+		formatNumber(s[0]), formatNumber(s[1]), formatNumber(s[2]),
+		m.Unit, formatNumber(float32(m.Volume)), m.Unit)
+}
+
+func (m Measurements) String() string {
+	u := m.Unit
+	b, o := m.Bounds, m.Oriented
+
+	return fmt.Sprintf(`size      %s %s
+bounds    %s .. %s %s
+oriented  %s %s
+volume    %s %s³
+area      %s %s²
+centroid  %s %s`,
+		formatVec3(b.Size()), u,
+		formatVec3(b.Min), formatVec3(b.Max), u,
+		formatVec3(o.Size()), u,
+		formatNumber(float32(m.Volume)), u,
+		formatNumber(float32(m.Area)), u,
+		formatVec3(m.Centroid), u)
+}
+
+// facetCorners
+// A facet's vertices in double precision.
+func facetCorners(triangle stl.Triangle) (a, b, c mgl64.Vec3) {
+	corner := func(v stl.Vec3) mgl64.Vec3 {
+		return mgl64.Vec3{float64(v[0]), float64(v[1]), float64(v[2])}
+	}
+
+	return corner(triangle.Vertices[0]), corner(triangle.Vertices[1]), corner(triangle.Vertices[2])
+}
+
+// symmetricEigenvectors
+// Diagonalises a symmetric 3x3 matrix with
+// cyclic Jacobi rotations, returning the
+// eigenvectors as unit axes.
+func symmetricEigenvectors(a [3][3]float64) (axes [3]mgl32.Vec3) {
+	v := [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+	for sweep := 0; sweep < 50; sweep++ {
+		off := a[0][1]*a[0][1] + a[0][2]*a[0][2] + a[1][2]*a[1][2]
+		if off < 1e-24 {
+			break
+		}
+
+		for p := 0; p < 2; p++ {
+			for q := p + 1; q < 3; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < 3; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < 3; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := 0; k < 3; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	for i := range axes {
+		axes[i] = mgl32.Vec3{float32(v[0][i]), float32(v[1][i]), float32(v[2][i])}.Normalize()
+	}
+
+	return
+}
+
+// formatNumber prints a length, area or
+// volume; the caller writes its unit.
+func formatNumber(x float32) string {
+	return fmt.Sprintf("%.2f", x)
+}
+
+func formatVec3(v mgl32.Vec3) string {
+	return fmt.Sprintf("%.2f, %.2f, %.2f", v[0], v[1], v[2])
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.5-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Mesh is a GPU resident triangle mesh
+// owning its vertex array, buffers and
+// the number of vertices to draw.
+type Mesh struct {
+	VertexArrayObject
+	vertices, elements Buffer
+
+	Name string
+
+	// Path is the file the mesh was loaded from.
+	Path string
+
+	// Indexed is the mesh data uploaded by
+	// NewMesh(), kept for export and analysis.
+	Indexed *IndexedMesh
+
+	// STL is the solid the mesh was built
+	// from, if any, for measuring and editing.
+	STL *STL
+
+	// Toolpath is the G-code view
+	// owning the mesh, if any.
+	Toolpath *ToolpathView
+
+	// Material applied before drawing;
+	// nil uses the DefaultMaterial.
+	Material *Material
+
+	// Transform places the mesh in the
+	// scene, ahead of the model matrix.
+	Transform mgl32.Mat4
+
+	// Bounds of the uploaded vertices,
+	// before Transform.
+	Bounds Bounds
+
+	// Overlay meshes are drawn after the
+	// scene without depth testing, to
+	// highlight parts of it.
+	Overlay bool
+
+	mode         uint32
+	first, count int32
+	indexed      bool
+}
+
+// NewMesh
+// Uploads an IndexedMesh in the interleaved
+// vertex layout and binds its attributes to
+// the inputs of program.
+func NewMesh(program Program, m *IndexedMesh) *Mesh {
+	mesh := newMesh(program, m.Vertices())
+
+	mesh.elements = GenBuffer(gl.ELEMENT_ARRAY_BUFFER)
+	mesh.elements.BufferData(len(m.Indices)*4, m.Indices, gl.STATIC_DRAW)
+	mesh.count = int32(len(m.Indices))
+	mesh.indexed = true
+	mesh.Indexed = m
+
+	return mesh
+}
+
+// NewArrayMesh
+// Uploads an already interleaved triangle
+// soup, such as *STL.Vertices(), to be
+// drawn without an index list.
+func NewArrayMesh(program Program, vertices []float32) *Mesh {
+	mesh := newMesh(program, vertices)
+	mesh.count = int32(len(vertices) / vertexFloats)
+	return mesh
+}
+
+// NewLineMesh
+// Uploads interleaved vertices where
+// every pair is drawn as a line.
+func NewLineMesh(program Program, vertices []float32) *Mesh {
+	mesh := NewArrayMesh(program, vertices)
+	mesh.mode = gl.LINES
+	return mesh
+}
+
+func newMesh(program Program, vertices []float32) *Mesh {
+	mesh := &Mesh{
+		VertexArrayObject: GenVertexArray(),
+		Transform:         mgl32.Ident4(),
+		Bounds:            vertexBounds(vertices),
+		mode:              gl.TRIANGLES,
+	}
+	mesh.BindVertexArray()
+
+	mesh.vertices = GenBuffer(gl.ARRAY_BUFFER)
+	if len(vertices) > 0 {
+		mesh.vertices.BufferData(len(vertices)*4, vertices, gl.STATIC_DRAW)
+	} else {
+		mesh.vertices.BindBuffer()
+	}
+
+	mesh.attrib(program, "vert", 3, vertexPositionOffset)
+	mesh.attrib(program, "vertNormal", 3, vertexNormalOffset)
+	mesh.attrib(program, "vertTexCoord", 2, vertexTexCoordOffset)
+	mesh.attrib(program, "vertColor", 4, vertexColorOffset)
+
+	return mesh
+}
+
+// attrib points a shader input at its slice of the
+// interleaved layout; inputs the shader does not
+// declare (or optimised away) are skipped.
+func (m *Mesh) attrib(
+	program Program, name string,
+	size int32, offset int,
+) {
+	location := program.GetAttribLocation(name)
+	if int32(location) < 0 {
+		return
+	}
+
+	location.EnableVertexAttribArray()
+	location.VertexAttribPointer(size, gl.FLOAT, false, vertexStride, offset)
+}
+
+// Count
+// Number of vertices submitted per Draw().
+func (m *Mesh) Count() int32 {
+	return m.count
+}
+
+// SetRange
+// Limits Draw() to count vertices (or indices)
+// from first; Upload() resets to all of them.
+func (m *Mesh) SetRange(first, count int32) {
+	m.first, m.count = first, count
+}
+
+// Upload
+// Replaces the vertices of a mesh made by
+// NewArrayMesh() or NewLineMesh().
+func (m *Mesh) Upload(vertices []float32) {
+	m.first, m.count = 0, int32(len(vertices)/vertexFloats)
+	m.Bounds = vertexBounds(vertices)
+	if len(vertices) > 0 {
+		m.vertices.BufferData(len(vertices)*4, vertices, gl.STATIC_DRAW)
+	}
+}
+
+// Draw
+// Binds the vertex array and draws
+// every primitive of the mesh.
+func (m *Mesh) Draw() {
+	m.BindVertexArray()
+
+	if m.count == 0 {
+		return
+	}
+
+	if m.indexed {
+		gl.DrawElements(m.mode, m.count, gl.UNSIGNED_INT, gl.PtrOffset(int(m.first)*4))
+	} else {
+		gl.DrawArrays(m.mode, m.first, m.count)
+	}
+}
+
+// Delete
+// Releases the GPU objects of the mesh.
+func (m *Mesh) Delete() {
+	m.vertices.DeleteBuffer()
+	if m.indexed {
+		m.elements.DeleteBuffer()
+	}
+	m.DeleteVertexArray()
+}
+
+// vertexBounds
+// The box of the positions of
+// interleaved vertices.
+func vertexBounds(vertices []float32) Bounds {
+	b := EmptyBounds()
+	for i := vertexPositionOffset / 4; i+2 < len(vertices); i += vertexFloats {
+		b = b.Extend(mgl32.Vec3{vertices[i], vertices[i+1], vertices[i+2]})
+	}
+
+	return b
+}
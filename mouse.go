@@ -6,7 +6,7 @@ package main
 import "github.com/go-gl/glfw/v3.1/glfw"
 
 type MouseButton int
-type Mouse [glfw.MouseButtonLast]bool
+type Mouse [glfw.MouseButtonLast + 1]bool
 
 //Mouse structure manipulation
 func (m *Mouse) glfwMouseButtonCallback(
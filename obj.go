@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// OBJ is a Wavefront OBJ scene split into
+// one IndexedMesh per group/object and
+// material, with the MTL materials it uses.
+type OBJ struct {
+	Objects   []*OBJObject
+	Materials map[string]*Material
+}
+
+// OBJObject is a run of faces sharing
+// an object/group name and a material.
+type OBJObject struct {
+	Name     string
+	Material *Material
+	*IndexedMesh
+}
+
+// OpenOBJ
+// Reads an OBJ file; material libraries and
+// textures are resolved relative to it.
+func OpenOBJ(file string) (*OBJ, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadOBJ(f, filepath.Dir(file))
+}
+
+// objCorner is the v/vt/vn triple of a face
+// corner, as zero based indices or -1.
+type objCorner [3]int
+
+type objBuilder struct {
+	object  *OBJObject
+	corners map[objCorner]uint32
+
+	// missing holds the vertices of
+	// corners written without a vn.
+	missing map[uint32]bool
+}
+
+// ReadOBJ
+// Parses OBJ data; dir is where mtllib
+// and map_Kd paths are looked up.
+func ReadOBJ(r io.Reader, dir string) (*OBJ, error) {
+	obj := &OBJ{Materials: map[string]*Material{}}
+
+	var positions, normals []mgl32.Vec3
+	var uvs []mgl32.Vec2
+
+	builders := map[string]*objBuilder{}
+	var order []*objBuilder
+	var current *objBuilder
+	name, material := "", ""
+
+	selectBuilder := func() {
+		key := name + "\x00" + material
+		if b, ok := builders[key]; ok {
+			current = b
+			return
+		}
+
+		current = &objBuilder{
+			object: &OBJObject{
+				Name:        name,
+				Material:    obj.Materials[material],
+				IndexedMesh: &IndexedMesh{},
+			},
+			corners: map[objCorner]uint32{},
+			missing: map[uint32]bool{},
+		}
+		builders[key] = current
+		order = append(order, current)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		args := fields[1:]
+		var err error
+
+		switch fields[0] {
+		case "v":
+			var v mgl32.Vec3
+			v, err = parseOBJVec3(args)
+			positions = append(positions, v)
+
+		case "vn":
+			var v mgl32.Vec3
+			v, err = parseOBJVec3(args)
+			normals = append(normals, v)
+
+		case "vt":
+			var v mgl32.Vec3
+			v, err = parseOBJVec3(append(args, "0", "0"))
+			// OBJ puts v=0 at the bottom of the
+			// image, GL textures at the first row.
+			uvs = append(uvs, mgl32.Vec2{v[0], 1 - v[1]})
+
+		case "o", "g":
+			name = strings.Join(args, " ")
+			current = nil
+
+		case "usemtl":
+			material = strings.Join(args, " ")
+			current = nil
+
+		case "mtllib":
+			// Exporters often name libraries they
+			// did not write; their materials fall
+			// back to the default.
+			for _, lib := range args {
+				if err := readMTL(filepath.Join(dir, lib), obj.Materials); err != nil {
+					log.Printf("obj: line %d: %v", line, err)
+				}
+			}
+
+		case "f":
+			if len(args) < 3 {
+				err = fmt.Errorf("face needs 3 corners, has %d", len(args))
+				break
+			}
+
+			if current == nil {
+				selectBuilder()
+			}
+
+			corners := make([]objCorner, len(args))
+			points := make([]mgl32.Vec3, len(args))
+			for i, arg := range args {
+				corners[i], err = parseOBJCorner(arg, len(positions), len(uvs), len(normals))
+				if err != nil {
+					break
+				}
+				points[i] = positions[corners[i][0]]
+			}
+
+			if err != nil {
+				break
+			}
+
+			for _, triangle := range triangulatePolygon(points) {
+				for _, i := range triangle {
+					current.add(corners[i], positions, uvs, normals)
+				}
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("obj: line %d: %v", line, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, b := range order {
+		if len(b.object.Indices) == 0 {
+			continue
+		}
+
+		if len(b.missing) > 0 {
+			b.fillNormals(DefaultCreaseAngle)
+		}
+
+		obj.Objects = append(obj.Objects, b.object)
+	}
+
+	return obj, nil
+}
+
+func (b *objBuilder) add(
+	corner objCorner,
+	positions []mgl32.Vec3,
+	uvs []mgl32.Vec2,
+	normals []mgl32.Vec3,
+) {
+	mesh := b.object.IndexedMesh
+
+	index, ok := b.corners[corner]
+	if !ok {
+		index = uint32(len(mesh.Positions))
+		b.corners[corner] = index
+
+		var uv mgl32.Vec2
+		if corner[1] >= 0 {
+			uv = uvs[corner[1]]
+		}
+
+		var normal mgl32.Vec3
+		if corner[2] >= 0 {
+			normal = normals[corner[2]]
+		} else {
+			b.missing[index] = true
+		}
+
+		mesh.Positions = append(mesh.Positions, positions[corner[0]])
+		mesh.UVs = append(mesh.UVs, uv)
+		mesh.Normals = append(mesh.Normals, normal)
+	}
+
+	mesh.Indices = append(mesh.Indices, index)
+}
+
+// fillNormals smooths normals for the corners
+// that lack a vn, leaving the file's own normals
+// alone. Faces are averaged across vertices at
+// the same position, whatever their vt or vn,
+// except over creases sharper than creaseDegrees.
+func (b *objBuilder) fillNormals(creaseDegrees float32) {
+	mesh := b.object.IndexedMesh
+
+	faceNormals := make([]mgl32.Vec3, len(mesh.Indices)/3)
+	incident := make(map[mgl32.Vec3][]int, len(mesh.Positions))
+	for f := range faceNormals {
+		corners := mesh.Indices[f*3 : f*3+3]
+		a, c, d := mesh.Positions[corners[0]], mesh.Positions[corners[1]], mesh.Positions[corners[2]]
+		faceNormals[f] = c.Sub(a).Cross(d.Sub(a))
+
+		for _, index := range corners {
+			p := mesh.Positions[index]
+			incident[p] = append(incident[p], f)
+		}
+	}
+
+	crease := float32(math.Cos(float64(mgl32.DegToRad(creaseDegrees))))
+
+	type corner struct {
+		index  uint32
+		normal mgl32.Vec3
+	}
+
+	split := map[corner]uint32{}
+	used := map[uint32]bool{}
+
+	for i, index := range mesh.Indices {
+		if !b.missing[index] {
+			continue
+		}
+
+		own := unitOrZero(faceNormals[i/3])
+		var normal mgl32.Vec3
+		for _, f := range incident[mesh.Positions[index]] {
+			if unitOrZero(faceNormals[f]).Dot(own) >= crease {
+				normal = normal.Add(faceNormals[f])
+			}
+		}
+		normal = unitOrZero(normal)
+
+		// The first normal reuses the vertex;
+		// others across a crease copy it.
+		key := corner{index, normal}
+		vertex, ok := split[key]
+		if !ok {
+			vertex = index
+			if used[index] {
+				vertex = uint32(len(mesh.Positions))
+				mesh.Positions = append(mesh.Positions, mesh.Positions[index])
+				mesh.UVs = append(mesh.UVs, mesh.UVs[index])
+				mesh.Normals = append(mesh.Normals, normal)
+			}
+
+			mesh.Normals[vertex] = normal
+			split[key] = vertex
+			used[index] = true
+		}
+
+		mesh.Indices[i] = vertex
+	}
+}
+
+// parseOBJCorner resolves "v", "v/vt", "v//vn"
+// and "v/vt/vn", including negative indices
+// counted back from the latest element.
+func parseOBJCorner(arg string, counts ...int) (corner objCorner, err error) {
+	parts := strings.Split(arg, "/")
+	if len(parts) > 3 {
+		return corner, fmt.Errorf("malformed face corner %q", arg)
+	}
+
+	for i := range corner {
+		corner[i] = -1
+		if i >= len(parts) || parts[i] == "" {
+			if i == 0 {
+				return corner, fmt.Errorf("face corner %q has no vertex", arg)
+			}
+			continue
+		}
+
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return corner, err
+		}
+
+		if n < 0 {
+			n += counts[i]
+		} else {
+			n--
+		}
+
+		if n < 0 || n >= counts[i] {
+			return corner, fmt.Errorf("face corner %q out of range", arg)
+		}
+
+		corner[i] = n
+	}
+
+	return
+}
+
+func parseOBJVec3(args []string) (v mgl32.Vec3, err error) {
+	if len(args) < 3 {
+		return v, fmt.Errorf("expected 3 components, got %d", len(args))
+	}
+
+	for i := range v {
+		f, err := strconv.ParseFloat(args[i], 32)
+		if err != nil {
+			return v, err
+		}
+		v[i] = float32(f)
+	}
+
+	return
+}
+
+// readMTL
+// Adds the materials of an MTL library;
+// map_Kd paths are made relative to it.
+func readMTL(file string, materials map[string]*Material) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var material *Material
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if fields[0] == "newmtl" {
+			material = &Material{
+				Name:      strings.Join(fields[1:], " "),
+				Diffuse:   DefaultMaterial.Diffuse,
+				Shininess: DefaultMaterial.Shininess,
+			}
+			materials[material.Name] = material
+			continue
+		}
+
+		if material == nil {
+			continue
+		}
+
+		switch fields[0] {
+		case "Kd":
+			material.Diffuse, err = parseOBJVec3(fields[1:])
+		case "Ks":
+			material.Specular, err = parseOBJVec3(fields[1:])
+		case "Ns":
+			var ns float64
+			ns, err = strconv.ParseFloat(strings.Join(fields[1:], ""), 32)
+			material.Shininess = float32(ns)
+		case "map_Kd":
+			// Options such as -s precede the
+			// file name, which comes last.
+			material.DiffuseMap = filepath.Join(
+				filepath.Dir(file), fields[len(fields)-1],
+			)
+		}
+
+		if err != nil {
+			return fmt.Errorf("mtl %s: line %d: %v", file, line, err)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestReadOBJMissingMaterials(t *testing.T) {
+	src := "mtllib missing.mtl\nusemtl red\nv 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"
+
+	obj, err := ReadOBJ(strings.NewReader(src), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(obj.Objects) != 1 || obj.Objects[0].Material != nil {
+		t.Fatalf("got %+v", obj.Objects)
+	}
+}
+
+func TestReadOBJPartialNormals(t *testing.T) {
+	// A flat pyramid: the base has its own normal,
+	// the sides none and a texture seam at every
+	// corner of the apex.
+	src := `v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+v 0.5 0.5 0.05
+vt 0 0
+vt 1 0
+vt 1 1
+vn 0 0 -1
+f 1//1 4//1 3//1 2//1
+f 1/1 2/2 5/3
+f 2/1 3/2 5/3
+f 3/1 4/2 5/3
+f 4/1 1/2 5/3
+`
+	obj, err := ReadOBJ(strings.NewReader(src), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := obj.Objects[0]
+	for _, index := range m.Indices[:6] {
+		if m.Normals[index] != (mgl32.Vec3{0, 0, -1}) {
+			t.Fatalf("base normal changed to %v", m.Normals[index])
+		}
+	}
+
+	for _, index := range m.Indices[6:] {
+		if p := m.Positions[index]; p[2] > 0 && !m.Normals[index].ApproxEqual(mgl32.Vec3{0, 0, 1}) {
+			t.Errorf("apex normal %v, want straight up", m.Normals[index])
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// plyProperty is a scalar or list
+// property of a PLY element.
+type plyProperty struct {
+	name string
+	typ  string
+
+	// countType is set for list properties.
+	countType string
+}
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// plyValues reads the next value of a
+// given PLY type, whatever the encoding.
+type plyValues interface {
+	next(typ string) (float64, error)
+}
+
+// OpenPLY
+// Reads an ASCII or binary (little or big
+// endian) PLY file into an IndexedMesh.
+func OpenPLY(file string) (*IndexedMesh, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadPLY(f)
+}
+
+// ReadPLY
+// Parses PLY data. Vertex positions, normals,
+// texture coordinates and colours are picked up
+// by name in any order; faces are triangulated.
+// Meshes without normals get SmoothNormals().
+func ReadPLY(r io.Reader) (*IndexedMesh, error) {
+	br := bufio.NewReader(r)
+
+	format, elements, err := readPLYHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	var values plyValues
+	switch format {
+	case "ascii":
+		scanner := bufio.NewScanner(br)
+		scanner.Split(bufio.ScanWords)
+		values = &plyASCII{scanner}
+	case "binary_little_endian":
+		values = &plyBinary{r: br, order: binary.LittleEndian}
+	case "binary_big_endian":
+		values = &plyBinary{r: br, order: binary.BigEndian}
+	default:
+		return nil, fmt.Errorf("ply: unknown format %q", format)
+	}
+
+	mesh := &IndexedMesh{}
+	var hasNormals, hasUVs, hasColors bool
+
+	for _, element := range elements {
+		for i := 0; i < element.count; i++ {
+			var vertex struct {
+				position, normal mgl32.Vec3
+				uv               mgl32.Vec2
+				color            mgl32.Vec4
+			}
+			vertex.color = white
+
+			for _, property := range element.properties {
+				if property.countType != "" {
+					list, err := readPLYList(values, property)
+					if err != nil {
+						return nil, fmt.Errorf("ply: %s %d: %v", element.name, i, err)
+					}
+
+					if element.name == "face" &&
+						(property.name == "vertex_indices" || property.name == "vertex_index") {
+						if err := mesh.addPLYFace(list); err != nil {
+							return nil, fmt.Errorf("ply: face %d: %v", i, err)
+						}
+					}
+					continue
+				}
+
+				v, err := values.next(property.typ)
+				if err != nil {
+					return nil, fmt.Errorf("ply: %s %d: %v", element.name, i, err)
+				}
+
+				if element.name != "vertex" {
+					continue
+				}
+
+				switch property.name {
+				case "x", "y", "z":
+					vertex.position[property.name[0]-'x'] = float32(v)
+				case "nx", "ny", "nz":
+					vertex.normal[property.name[1]-'x'] = float32(v)
+					hasNormals = true
+				case "u", "s", "texture_u", "texture_s":
+					vertex.uv[0] = float32(v)
+					hasUVs = true
+				case "v", "t", "texture_v", "texture_t":
+					// Flipped like OBJ; see ReadOBJ().
+					vertex.uv[1] = 1 - float32(v)
+					hasUVs = true
+				case "red", "r", "diffuse_red":
+					vertex.color[0] = plyColor(v, property.typ)
+					hasColors = true
+				case "green", "g", "diffuse_green":
+					vertex.color[1] = plyColor(v, property.typ)
+					hasColors = true
+				case "blue", "b", "diffuse_blue":
+					vertex.color[2] = plyColor(v, property.typ)
+					hasColors = true
+				case "alpha", "a", "diffuse_alpha":
+					vertex.color[3] = plyColor(v, property.typ)
+				}
+			}
+
+			if element.name == "vertex" {
+				mesh.Positions = append(mesh.Positions, vertex.position)
+				mesh.Normals = append(mesh.Normals, vertex.normal)
+				mesh.UVs = append(mesh.UVs, vertex.uv)
+				mesh.Colors = append(mesh.Colors, vertex.color)
+			}
+		}
+	}
+
+	if !hasUVs {
+		mesh.UVs = nil
+	}
+
+	if !hasColors {
+		mesh.Colors = nil
+	}
+
+	if !hasNormals {
+		mesh.Normals = nil
+		mesh.SmoothNormals(DefaultCreaseAngle)
+	}
+
+	return mesh, nil
+}
+
+// addPLYFace triangulates a face
+// given as indices into Positions.
+func (m *IndexedMesh) addPLYFace(list []float64) error {
+	points := make([]mgl32.Vec3, len(list))
+	for i, v := range list {
+		if v < 0 || int(v) >= len(m.Positions) {
+			return fmt.Errorf("vertex index %v out of range", v)
+		}
+		points[i] = m.Positions[int(v)]
+	}
+
+	for _, triangle := range triangulatePolygon(points) {
+		for _, i := range triangle {
+			m.Indices = append(m.Indices, uint32(list[i]))
+		}
+	}
+
+	return nil
+}
+
+func readPLYHeader(r *bufio.Reader) (format string, elements []plyElement, err error) {
+	line := func() (string, error) {
+		s, err := r.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("ply: header: %v", err)
+		}
+		return strings.TrimRight(s, "\r\n"), nil
+	}
+
+	magic, err := line()
+	if err != nil {
+		return
+	}
+
+	if magic != "ply" {
+		return "", nil, errors.New("ply: missing magic number")
+	}
+
+	for {
+		var s string
+		if s, err = line(); err != nil {
+			return
+		}
+
+		fields := strings.Fields(s)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return "", nil, fmt.Errorf("ply: malformed %q", s)
+			}
+			format = fields[1]
+
+		case "element":
+			if len(fields) != 3 {
+				return "", nil, fmt.Errorf("ply: malformed %q", s)
+			}
+
+			var count int
+			if count, err = strconv.Atoi(fields[2]); err != nil {
+				return "", nil, fmt.Errorf("ply: malformed %q", s)
+			}
+			elements = append(elements, plyElement{name: fields[1], count: count})
+
+		case "property":
+			if len(elements) == 0 {
+				return "", nil, fmt.Errorf("ply: property before element: %q", s)
+			}
+
+			element := &elements[len(elements)-1]
+			switch {
+			case len(fields) == 5 && fields[1] == "list":
+				element.properties = append(element.properties, plyProperty{
+					name: fields[4], typ: fields[3], countType: fields[2],
+				})
+			case len(fields) == 3:
+				element.properties = append(element.properties, plyProperty{
+					name: fields[2], typ: fields[1],
+				})
+			default:
+				return "", nil, fmt.Errorf("ply: malformed %q", s)
+			}
+
+		case "end_header":
+			return
+		}
+	}
+}
+
+// plyMaxList bounds the length of a list
+// property, so a corrupt count cannot ask
+// for gigabytes before the data runs out.
+const plyMaxList = 1 << 16
+
+func readPLYList(values plyValues, property plyProperty) ([]float64, error) {
+	n, err := values.next(property.countType)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 0 || n > plyMaxList || n != math.Trunc(n) {
+		return nil, fmt.Errorf("ply: bad %s list length %v", property.name, n)
+	}
+
+	list := make([]float64, int(n))
+	for i := range list {
+		if list[i], err = values.next(property.typ); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// plyColor normalises an integer colour
+// channel to 0-1; floats are kept as is.
+func plyColor(v float64, typ string) float32 {
+	switch typ {
+	case "uchar", "uint8", "char", "int8":
+		return float32(v / 255)
+	case "ushort", "uint16", "short", "int16":
+		return float32(v / 65535)
+	}
+
+	return float32(v)
+}
+
+type plyASCII struct {
+	*bufio.Scanner
+}
+
+func (a *plyASCII) next(typ string) (float64, error) {
+	if !a.Scan() {
+		if err := a.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return strconv.ParseFloat(a.Text(), 64)
+}
+
+type plyBinary struct {
+	r     io.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (b *plyBinary) next(typ string) (float64, error) {
+	size := 0
+	switch typ {
+	case "char", "int8", "uchar", "uint8":
+		size = 1
+	case "short", "int16", "ushort", "uint16":
+		size = 2
+	case "int", "int32", "uint", "uint32", "float", "float32":
+		size = 4
+	case "double", "float64":
+		size = 8
+	default:
+		return 0, fmt.Errorf("unknown type %q", typ)
+	}
+
+	buf := b.buf[:size]
+	if _, err := io.ReadFull(b.r, buf); err != nil {
+		return 0, err
+	}
+
+	switch typ {
+	case "char", "int8":
+		return float64(int8(buf[0])), nil
+	case "uchar", "uint8":
+		return float64(buf[0]), nil
+	case "short", "int16":
+		return float64(int16(b.order.Uint16(buf))), nil
+	case "ushort", "uint16":
+		return float64(b.order.Uint16(buf)), nil
+	case "int", "int32":
+		return float64(int32(b.order.Uint32(buf))), nil
+	case "uint", "uint32":
+		return float64(b.order.Uint32(buf)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(b.order.Uint32(buf))), nil
+	}
+
+	return math.Float64frombits(b.order.Uint64(buf)), nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// plyQuad is a unit square in the XY plane
+// with red, green, blue and white corners.
+var plyQuad = struct {
+	positions []mgl32.Vec3
+	colors    [][3]uint8
+}{
+	[]mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}},
+	[][3]uint8{{255, 0, 0}, {0, 255, 0}, {0, 0, 255}, {255, 255, 255}},
+}
+
+func plyQuadHeader(format, countType string) string {
+	return "ply\nformat " + format + " 1.0\ncomment a quad\n" +
+		"element vertex 4\nproperty float x\nproperty float y\nproperty float z\n" +
+		"property uchar red\nproperty uchar green\nproperty uchar blue\n" +
+		"element face 1\nproperty list " + countType + " int vertex_indices\nend_header\n"
+}
+
+func plyQuadBinary(order binary.ByteOrder, format string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(plyQuadHeader(format, "uchar"))
+	for i, p := range plyQuad.positions {
+		binary.Write(&buf, order, p)
+		buf.Write(plyQuad.colors[i][:])
+	}
+	buf.WriteByte(4)
+	binary.Write(&buf, order, []int32{0, 1, 2, 3})
+	return buf.Bytes()
+}
+
+func TestReadPLY(t *testing.T) {
+	ascii := plyQuadHeader("ascii", "uchar") +
+		"0 0 0 255 0 0\n1 0 0 0 255 0\n1 1 0 0 0 255\n0 1 0 255 255 255\n4 0 1 2 3\n"
+
+	for _, test := range []struct {
+		name string
+		data []byte
+	}{
+		{"ascii", []byte(ascii)},
+		{"binary little endian", plyQuadBinary(binary.LittleEndian, "binary_little_endian")},
+		{"binary big endian", plyQuadBinary(binary.BigEndian, "binary_big_endian")},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			mesh, err := ReadPLY(bytes.NewReader(test.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(mesh.Indices) != 6 {
+				t.Fatalf("%d indices, want two triangles", len(mesh.Indices))
+			}
+
+			// Smoothing may split vertices, so look
+			// the corners up through the indices.
+			for _, index := range mesh.Indices {
+				p := mesh.Positions[index]
+				found := false
+				for i, want := range plyQuad.positions {
+					if p == want {
+						c := plyQuad.colors[i]
+						color := mgl32.Vec4{float32(c[0]) / 255, float32(c[1]) / 255, float32(c[2]) / 255, 1}
+						if mesh.Colors[index] != color {
+							t.Errorf("corner %v colour %v, want %v", p, mesh.Colors[index], color)
+						}
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("unexpected corner %v", p)
+				}
+
+				if n := mesh.Normals[index]; !n.ApproxEqual(mgl32.Vec3{0, 0, 1}) {
+					t.Errorf("corner %v normal %v", p, n)
+				}
+			}
+		})
+	}
+}
+
+func TestReadPLYErrors(t *testing.T) {
+	vertices := "0 0 0 255 0 0\n1 0 0 0 255 0\n1 1 0 0 0 255\n0 1 0 255 255 255\n"
+
+	for _, test := range []struct {
+		name, data string
+	}{
+		{"negative count", plyQuadHeader("ascii", "int") + vertices + "-4 0 1 2 3\n"},
+		{"huge count", plyQuadHeader("ascii", "int") + vertices + "2000000000 0 1 2 3\n"},
+		{"fractional count", plyQuadHeader("ascii", "float") + vertices + "3.5 0 1 2\n"},
+		{"index out of range", plyQuadHeader("ascii", "uchar") + vertices + "3 0 1 9\n"},
+		{"truncated", plyQuadHeader("ascii", "uchar") + vertices + "4 0 1\n"},
+		{"unknown format", plyQuadHeader("binary_middle_endian", "uchar")},
+		{"not a ply", "solid cube\n"},
+	} {
+		if _, err := ReadPLY(strings.NewReader(test.data)); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hschendel/stl"
+)
+
+// RepairStep selects the operations
+// run by STL.Repair; combine with |.
+type RepairStep int
+
+const (
+	// RepairMergeVertices snaps vertices closer
+	// than epsilon onto a single position.
+	RepairMergeVertices RepairStep = 1 << iota
+
+	// RepairRemoveDegenerate drops facets
+	// with (near) zero area.
+	RepairRemoveDegenerate
+
+	// RepairRemoveDuplicates drops facets repeating
+	// the corners of an earlier one.
+	RepairRemoveDuplicates
+
+	// RepairUnifyWinding flood fills each shell
+	// from its first facet, flipping neighbours
+	// that traverse a shared edge the same way.
+	RepairUnifyWinding
+
+	// RepairFlipInsideOut reverses shells whose
+	// signed volume is negative.
+	RepairFlipInsideOut
+
+	// RepairFillHoles closes simple boundary
+	// loops by triangulating them.
+	RepairFillHoles
+
+	RepairAll = RepairMergeVertices | RepairRemoveDegenerate |
+		RepairRemoveDuplicates | RepairUnifyWinding |
+		RepairFlipInsideOut | RepairFillHoles
+)
+
+// RepairReport counts what each step changed.
+type RepairReport struct {
+	MergedVertices    int
+	RemovedDegenerate int
+	RemovedDuplicates int
+	FlippedFacets     int
+	FlippedShells     int
+	FilledHoles       int
+	AddedFacets       int
+}
+
+// Changed
+// Whether any step altered the solid.
+func (r RepairReport) Changed() bool {
+	return r != RepairReport{}
+}
+
+func (r RepairReport) String() string {
+	if !r.Changed() {
+		return "nothing to repair"
+	}
+
+	var parts []string
+	for _, change := range []struct {
+		name  string
+		count int
+	}{
+		{"vertices merged", r.MergedVertices},
+		{"degenerate facets removed", r.RemovedDegenerate},
+		{"duplicate facets removed", r.RemovedDuplicates},
+		{"facets rewound", r.FlippedFacets},
+		{"shells turned outside in", r.FlippedShells},
+		{"holes filled", r.FilledHoles},
+		{"facets added", r.AddedFacets},
+	} {
+		if change.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", change.count, change.name))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// repairMesh is the working state of a repair:
+// the facets, their welded corners and the
+// welded positions they refer to.
+type repairMesh struct {
+	triangles []stl.Triangle
+	corners   [][3]uint32
+	positions []mgl32.Vec3
+}
+
+// Repair
+// Runs the selected steps in a fixed order (merge,
+// degenerate, duplicates, winding, inside-out,
+// holes), welding vertices within epsilon to find
+// shared edges. Normals are recomputed if anything
+// changed.
+func (s *STL) Repair(steps RepairStep, epsilon float32) (report RepairReport) {
+	m := newRepairMesh(s, epsilon)
+
+	if steps&RepairMergeVertices != 0 {
+		report.MergedVertices = len(s.Indexed(0).Positions) - len(m.positions)
+		if report.MergedVertices > 0 {
+			for t := range m.triangles {
+				for i, c := range m.corners[t] {
+					m.triangles[t].Vertices[i] = stl.Vec3(m.positions[c])
+				}
+			}
+		}
+	}
+
+	if steps&RepairRemoveDegenerate != 0 {
+		report.RemovedDegenerate = m.filter(func(t int) bool {
+			c := m.corners[t]
+			return c[0] != c[1] && c[1] != c[2] && c[2] != c[0] &&
+				m.area(t) > epsilon*epsilon
+		})
+	}
+
+	if steps&RepairRemoveDuplicates != 0 {
+		seen := map[[3]uint32]bool{}
+		report.RemovedDuplicates = m.filter(func(t int) bool {
+			key := m.corners[t]
+			sort.Slice(key[:], func(i, j int) bool { return key[i] < key[j] })
+			if seen[key] {
+				return false
+			}
+			seen[key] = true
+			return true
+		})
+	}
+
+	if steps&RepairUnifyWinding != 0 {
+		report.FlippedFacets = m.unifyWinding()
+	}
+
+	if steps&RepairFlipInsideOut != 0 {
+		for _, shell := range m.shells() {
+			if m.signedVolume(shell) < 0 {
+				for _, t := range shell {
+					m.flip(t)
+				}
+				report.FlippedShells++
+			}
+		}
+	}
+
+	if steps&RepairFillHoles != 0 {
+		before := len(m.triangles)
+		report.FilledHoles = m.fillHoles()
+		report.AddedFacets = len(m.triangles) - before
+	}
+
+	s.Triangles = m.triangles
+	if report.Changed() {
+		for t := range s.Triangles {
+			s.Triangles[t].Normal = windingNormal(s.Triangles[t])
+		}
+		s.modified = true
+	}
+
+	return
+}
+
+// newRepairMesh
+// Welds the solid within epsilon, sharing
+// (not copying) its facets.
+func newRepairMesh(s *STL, epsilon float32) *repairMesh {
+	welded := s.Indexed(epsilon)
+	m := &repairMesh{
+		triangles: s.Triangles,
+		corners:   make([][3]uint32, len(s.Triangles)),
+		positions: welded.Positions,
+	}
+
+	for t := range m.corners {
+		copy(m.corners[t][:], welded.Indices[t*3:t*3+3])
+	}
+
+	return m
+}
+
+// filter keeps the facets for which keep is
+// true and returns how many were dropped.
+func (m *repairMesh) filter(keep func(t int) bool) int {
+	n := 0
+	for t := range m.triangles {
+		if keep(t) {
+			m.triangles[n], m.corners[n] = m.triangles[t], m.corners[t]
+			n++
+		}
+	}
+
+	removed := len(m.triangles) - n
+	m.triangles, m.corners = m.triangles[:n], m.corners[:n]
+	return removed
+}
+
+func (m *repairMesh) flip(t int) {
+	v := &m.triangles[t].Vertices
+	v[1], v[2] = v[2], v[1]
+	c := &m.corners[t]
+	c[1], c[2] = c[2], c[1]
+}
+
+func (m *repairMesh) area(t int) float32 {
+	v := m.triangles[t].Vertices
+	a := mgl32.Vec3(v[0])
+	return mgl32.Vec3(v[1]).Sub(a).Cross(mgl32.Vec3(v[2]).Sub(a)).Len() / 2
+}
+
+// edges maps every undirected welded
+// edge to the facets using it.
+func (m *repairMesh) edges() map[[2]uint32][]int {
+	edges := make(map[[2]uint32][]int, len(m.triangles)*3/2)
+	for t, c := range m.corners {
+		for i := range c {
+			edges[undirected(c[i], c[(i+1)%3])] = append(edges[undirected(c[i], c[(i+1)%3])], t)
+		}
+	}
+
+	return edges
+}
+
+// traverses reports whether facet t runs
+// along the edge from a to b.
+func (m *repairMesh) traverses(t int, a, b uint32) bool {
+	c := m.corners[t]
+	for i := range c {
+		if c[i] == a && c[(i+1)%3] == b {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unifyWinding orients every shell like its first
+// facet; only manifold edges propagate the fill.
+func (m *repairMesh) unifyWinding() (flipped int) {
+	edges := m.edges()
+	visited := make([]bool, len(m.triangles))
+
+	for start := range m.triangles {
+		if visited[start] {
+			continue
+		}
+
+		visited[start] = true
+		queue := []int{start}
+
+		for len(queue) > 0 {
+			t := queue[0]
+			queue = queue[1:]
+
+			c := m.corners[t]
+			for i := range c {
+				a, b := c[i], c[(i+1)%3]
+				shared := edges[undirected(a, b)]
+				if len(shared) != 2 {
+					continue
+				}
+
+				n := shared[0]
+				if n == t {
+					n = shared[1]
+				}
+
+				if visited[n] {
+					continue
+				}
+
+				visited[n] = true
+				if m.traverses(n, a, b) {
+					m.flip(n)
+					flipped++
+				}
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	return
+}
+
+// shells groups facets connected through
+// shared edges, in order of first facet.
+func (m *repairMesh) shells() (shells [][]int) {
+	edges := m.edges()
+	shell := make([]int, len(m.triangles))
+	for t := range shell {
+		shell[t] = -1
+	}
+
+	for start := range m.triangles {
+		if shell[start] >= 0 {
+			continue
+		}
+
+		id := len(shells)
+		shell[start] = id
+		members := []int{start}
+
+		for i := 0; i < len(members); i++ {
+			c := m.corners[members[i]]
+			for j := range c {
+				for _, n := range edges[undirected(c[j], c[(j+1)%3])] {
+					if shell[n] < 0 {
+						shell[n] = id
+						members = append(members, n)
+					}
+				}
+			}
+		}
+
+		shells = append(shells, members)
+	}
+
+	return
+}
+
+// signedVolume sums the tetrahedra spanned by the
+// origin and each facet; positive for outward
+// facing closed shells.
+func (m *repairMesh) signedVolume(shell []int) (volume float64) {
+	for _, t := range shell {
+		volume += facetVolume(m.triangles[t])
+	}
+
+	return
+}
+
+// fillHoles triangulates every boundary loop
+// whose vertices each start one hole edge only.
+func (m *repairMesh) fillHoles() (filled int) {
+	next := map[uint32]uint32{}
+	ambiguous := map[uint32]bool{}
+
+	for edge, facets := range m.edges() {
+		if len(facets) != 1 {
+			continue
+		}
+
+		// The patch runs along the hole edge in
+		// the opposite direction to its facet.
+		a, b := edge[0], edge[1]
+		if m.traverses(facets[0], a, b) {
+			a, b = b, a
+		}
+
+		if _, ok := next[a]; ok {
+			ambiguous[a] = true
+		}
+		next[a] = b
+	}
+
+	starts := make([]uint32, 0, len(next))
+	for v := range next {
+		starts = append(starts, v)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	visited := map[uint32]bool{}
+	for _, start := range starts {
+		if visited[start] {
+			continue
+		}
+
+		loop := []uint32{start}
+		simple := !ambiguous[start]
+		visited[start] = true
+
+		for v, ok := next[start]; ok && v != start; v, ok = next[v] {
+			if visited[v] || ambiguous[v] {
+				simple = false
+				break
+			}
+			visited[v] = true
+			loop = append(loop, v)
+		}
+
+		if !simple || len(loop) < 3 || next[loop[len(loop)-1]] != start {
+			continue
+		}
+
+		points := make([]mgl32.Vec3, len(loop))
+		for i, v := range loop {
+			points[i] = m.positions[v]
+		}
+
+		for _, t := range triangulatePolygon(points) {
+			triangle := stl.Triangle{}
+			var corners [3]uint32
+			for i, p := range t {
+				triangle.Vertices[i] = stl.Vec3(points[p])
+				corners[i] = loop[p]
+			}
+
+			m.triangles = append(m.triangles, triangle)
+			m.corners = append(m.corners, corners)
+		}
+
+		filled++
+	}
+
+	return
+}
+
+// facetVolume is the signed volume of the
+// tetrahedron from the origin to a facet.
+func facetVolume(triangle stl.Triangle) float64 {
+	a, b, c := facetCorners(triangle)
+	return a.Dot(b.Cross(c)) / 6
+}
+
+func undirected(a, b uint32) [2]uint32 {
+	if a > b {
+		return [2]uint32{b, a}
+	}
+
+	return [2]uint32{a, b}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hschendel/stl"
+)
+
+func TestRepair(t *testing.T) {
+	const epsilon = 1e-3
+
+	for _, test := range []struct {
+		name   string
+		damage func(s *STL)
+		want   RepairReport
+	}{
+		{"intact", func(s *STL) {}, RepairReport{}},
+		{
+			"split vertex",
+			func(s *STL) { s.Triangles[0].Vertices[0][0] += epsilon / 10 },
+			RepairReport{MergedVertices: 1},
+		},
+		{
+			"degenerate facet",
+			func(s *STL) {
+				s.Triangles = append(s.Triangles, stl.Triangle{
+					Vertices: [3]stl.Vec3{{0, 0, 0}, {5, 0, 0}, {10, 0, 0}},
+				})
+			},
+			RepairReport{RemovedDegenerate: 1},
+		},
+		{
+			"duplicate facet",
+			func(s *STL) { s.Triangles = append(s.Triangles, s.Triangles[3]) },
+			RepairReport{RemovedDuplicates: 1},
+		},
+		{
+			"one facet wound backwards",
+			func(s *STL) { flipFacet(&s.Triangles[5]) },
+			RepairReport{FlippedFacets: 1},
+		},
+		{
+			"inside out",
+			func(s *STL) {
+				for i := range s.Triangles {
+					flipFacet(&s.Triangles[i])
+				}
+			},
+			RepairReport{FlippedShells: 1},
+		},
+		{
+			"hole",
+			func(s *STL) { s.Triangles = s.Triangles[1:] },
+			RepairReport{FilledHoles: 1, AddedFacets: 1},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := prism(square(0, 10), nil, 10)
+			test.damage(s)
+
+			if report := s.Repair(RepairAll, epsilon); report != test.want {
+				t.Errorf("report %+v, want %+v", report, test.want)
+			}
+
+			if report := s.Analyze(epsilon); !report.Printable() {
+				t.Errorf("still not printable: %v", report)
+			}
+
+			if v := s.Volume(); math.Abs(v-1000) > 1e-2 {
+				t.Errorf("volume %v, want 1000", v)
+			}
+		})
+	}
+}
+
+func flipFacet(triangle *stl.Triangle) {
+	triangle.Vertices[1], triangle.Vertices[2] = triangle.Vertices[2], triangle.Vertices[1]
+	triangle.Normal = windingNormal(*triangle)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hschendel/stl"
+)
+
+// Split
+// Separates the solid into its connected shells,
+// welding vertices within epsilon to find the
+// edges they share. Shells come in order of their
+// first facet and keep the binary/ASCII encoding;
+// renamed parts get a header of their own name
+// that keeps only the colour tags of the original.
+func (s *STL) Split(epsilon float32) []*STL {
+	m := newRepairMesh(s, epsilon)
+	shells := m.shells()
+
+	parts := make([]*STL, len(shells))
+	for i, shell := range shells {
+		part := &STL{modified: s.modified}
+		part.Name = s.Name
+		part.BinaryHeader = append([]byte(nil), s.BinaryHeader...)
+		if len(shells) > 1 && s.Name != "" {
+			part.Name = fmt.Sprintf("%s_%d", s.Name, i+1)
+			part.BinaryHeader = s.renamedHeader(part.Name)
+		}
+		part.IsAscii = s.IsAscii
+
+		part.Triangles = make([]stl.Triangle, len(shell))
+		for j, t := range shell {
+			part.Triangles[j] = s.Triangles[t]
+		}
+
+		parts[i] = part
+	}
+
+	return parts
+}
+
+// MergeSTL
+// Combines solids into one, placing each by the
+// transform at the same index; solids past the
+// end of transforms are merged in place. The
+// name and colour tags of the header (and so
+// the colour convention) come from the first
+// solid; the rest of its header text is not kept.
+func MergeSTL(solids []*STL, transforms ...mgl32.Mat4) *STL {
+	merged := &STL{modified: true}
+	if len(solids) == 0 {
+		return merged
+	}
+
+	merged.Name = solids[0].Name
+	merged.BinaryHeader = solids[0].renamedHeader(merged.Name)
+	merged.IsAscii = solids[0].IsAscii
+
+	for i, solid := range solids {
+		start := len(merged.Triangles)
+		merged.Triangles = append(merged.Triangles, solid.Triangles...)
+
+		if i < len(transforms) {
+			part := &STL{}
+			part.Triangles = merged.Triangles[start:]
+			part.Transform(transforms[i])
+		}
+	}
+
+	return merged
+}
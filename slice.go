@@ -0,0 +1,446 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Polygon is a closed loop in a layer plane,
+// its last point joining back to the first.
+type Polygon []mgl32.Vec2
+
+// Area
+// The signed (shoelace) area; positive for
+// counter-clockwise loops seen from +Z.
+func (p Polygon) Area() float32 {
+	var area float32
+	for i, a := range p {
+		b := p[(i+1)%len(p)]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+
+	return area / 2
+}
+
+// Hole
+// Whether the loop runs clockwise.
+func (p Polygon) Hole() bool {
+	return p.Area() < 0
+}
+
+// Contains
+// Whether pt lies inside the loop,
+// by the even-odd crossing rule.
+func (p Polygon) Contains(pt mgl32.Vec2) bool {
+	inside := false
+	for i, a := range p {
+		b := p[(i+1)%len(p)]
+		if (a[1] > pt[1]) != (b[1] > pt[1]) &&
+			pt[0] < a[0]+(pt[1]-a[1])*(b[0]-a[0])/(b[1]-a[1]) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Reverse
+// Turns the loop the other way, in place.
+func (p Polygon) Reverse() {
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+}
+
+// Offset
+// Moves every edge of the loops d to its left,
+// into the material of a correctly oriented
+// layer, mitring the corners. Where a loop
+// crosses itself (a feature thinner than 2d)
+// it is split at the crossings; pieces with an
+// edge running against the edge it was moved
+// from have been turned over by the offset and
+// are dropped, which also drops loops that
+// collapse altogether.
+func Offset(polygons []Polygon, d float32) (offset []Polygon) {
+	for _, p := range polygons {
+		p = p.clean()
+		if len(p) < 3 {
+			continue
+		}
+
+		out := offsetLoop{make(Polygon, len(p)), make([]int, len(p))}
+		for i, cur := range p {
+			prev, next := p[(i+len(p)-1)%len(p)], p[(i+1)%len(p)]
+			n1 := leftNormal(cur.Sub(prev))
+			n2 := leftNormal(next.Sub(cur))
+
+			// The corner moves along the bisector far
+			// enough for both edges to move by d;
+			// spikes are clamped to a few widths.
+			miter := n1.Add(n2).Mul(d / float32(math.Max(float64(1+n1.Dot(n2)), 1e-6)))
+			if limit := 4 * float32(math.Abs(float64(d))); miter.Len() > limit {
+				miter = miter.Normalize().Mul(limit)
+			}
+
+			out.points[i] = cur.Add(miter)
+			out.edges[i] = i
+		}
+
+		a := p.Area()
+		for _, piece := range out.untangle() {
+			b := piece.points.Area()
+			if (a > 0) == (b > 0) && math.Abs(float64(b)) >= 1e-9 && piece.follows(p) {
+				offset = append(offset, piece.points)
+			}
+		}
+	}
+
+	return
+}
+
+// offsetLoop is a loop being offset; its edge
+// k runs along the moved source edge edges[k].
+type offsetLoop struct {
+	points Polygon
+	edges  []int
+}
+
+// untangle splits the loop at the first place
+// two of its edges cross, and the two loops
+// either side of the crossing in turn, until
+// none of the pieces cross themselves.
+func (l offsetLoop) untangle() []offsetLoop {
+	p, n := l.points, len(l.points)
+	for i := 0; i < n; i++ {
+		a, b := p[i], p[(i+1)%n]
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+
+			x, ok := segmentCrossing(a, b, p[j], p[(j+1)%n])
+			if !ok {
+				continue
+			}
+
+			// Each side keeps part of both
+			// crossing edges, from or to x.
+			inner := offsetLoop{
+				append(Polygon{x}, p[i+1:j+1]...),
+				append([]int{l.edges[i]}, l.edges[i+1:j+1]...),
+			}
+			outer := offsetLoop{
+				append(append(append(Polygon{}, p[:i+1]...), x), p[j+1:]...),
+				append(append(append([]int{}, l.edges[:i+1]...), l.edges[j]), l.edges[j+1:]...),
+			}
+			return append(outer.untangle(), inner.untangle()...)
+		}
+	}
+
+	return []offsetLoop{l}
+}
+
+// follows is true when no edge of the loop
+// points against its edge of source.
+func (l offsetLoop) follows(source Polygon) bool {
+	for k, pt := range l.points {
+		e := l.edges[k]
+		moved := l.points[(k+1)%len(l.points)].Sub(pt)
+		if moved.Dot(source[(e+1)%len(source)].Sub(source[e])) < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// segmentCrossing is the point where ab and cd
+// cross strictly inside both; touching ends and
+// parallel segments do not count.
+func segmentCrossing(a, b, c, d mgl32.Vec2) (mgl32.Vec2, bool) {
+	r, s := b.Sub(a), d.Sub(c)
+	denom := float64(r[0]*s[1] - r[1]*s[0])
+	if math.Abs(denom) < 1e-12 {
+		return mgl32.Vec2{}, false
+	}
+
+	ac := c.Sub(a)
+	t := float64(ac[0]*s[1]-ac[1]*s[0]) / denom
+	u := float64(ac[0]*r[1]-ac[1]*r[0]) / denom
+	const eps = 1e-6
+	if t <= eps || t >= 1-eps || u <= eps || u >= 1-eps {
+		return mgl32.Vec2{}, false
+	}
+
+	return a.Add(r.Mul(float32(t))), true
+}
+
+// clean drops repeated points, which have no
+// direction to offset along, and points in the
+// middle of straight runs, which offset past
+// the corners next to them.
+func (p Polygon) clean() Polygon {
+	out := make(Polygon, 0, len(p))
+	for i, pt := range p {
+		if pt.ApproxEqualThreshold(p[(i+1)%len(p)], 1e-6) {
+			continue
+		}
+		out = append(out, pt)
+	}
+
+	for changed := true; changed && len(out) >= 3; {
+		changed = false
+		for i := 0; i < len(out) && len(out) >= 3; i++ {
+			prev, cur, next := out[(i+len(out)-1)%len(out)], out[i], out[(i+1)%len(out)]
+			a, b := cur.Sub(prev), next.Sub(cur)
+			if math.Abs(float64(a[0]*b[1]-a[1]*b[0])) <= 1e-6*float64(a.Len()*b.Len()) && a.Dot(b) > 0 {
+				out = append(out[:i], out[i+1:]...)
+				changed = true
+				i--
+			}
+		}
+	}
+
+	return out
+}
+
+func leftNormal(d mgl32.Vec2) mgl32.Vec2 {
+	if l := d.Len(); l > 0 {
+		return mgl32.Vec2{-d[1] / l, d[0] / l}
+	}
+
+	return mgl32.Vec2{}
+}
+
+// Layer is the cross-section of a solid at
+// height Z: outer loops counter-clockwise,
+// holes clockwise, so an even-odd or non-zero
+// fill of Polygons gives the material.
+type Layer struct {
+	Z        float32
+	Polygons []Polygon
+}
+
+// Slice
+// Cuts the solid into layers of the given
+// height from its lowest point, each plane
+// taken through the middle of its layer.
+func (s *STL) Slice(layerHeight float32) []Layer {
+	b := s.Bounds()
+	if b.Empty() || layerHeight <= 0 {
+		return nil
+	}
+
+	m := newRepairMesh(s, DefaultWeldEpsilon)
+	count := int(math.Ceil(float64((b.Max[2] - b.Min[2]) / layerHeight)))
+
+	layers := make([]Layer, 0, count)
+	for i := 0; i < count; i++ {
+		layers = append(layers, m.slice(b.Min[2]+(float32(i)+0.5)*layerHeight))
+	}
+
+	return layers
+}
+
+// SliceAt
+// The cross-section through the plane at z.
+func (s *STL) SliceAt(z float32) Layer {
+	return newRepairMesh(s, DefaultWeldEpsilon).slice(z)
+}
+
+// sliceSegment runs between the points where
+// the plane cuts two welded edges of a facet.
+type sliceSegment struct {
+	from, to [2]uint32
+	a, b     mgl32.Vec2
+}
+
+// slice intersects every facet with the plane at z
+// and chains the segments through shared edges;
+// chains that do not close (open meshes) are dropped.
+func (m *repairMesh) slice(z float32) Layer {
+	var segments []sliceSegment
+
+	for t, triangle := range m.triangles {
+		c := m.corners[t]
+
+		// A vertex on the plane counts as above it,
+		// so every cut edge has one end each side.
+		var points []mgl32.Vec2
+		var edges [][2]uint32
+		for i := range c {
+			p, q := mgl32.Vec3(triangle.Vertices[i]), mgl32.Vec3(triangle.Vertices[(i+1)%3])
+			if (p[2] >= z) == (q[2] >= z) {
+				continue
+			}
+
+			f := (z - p[2]) / (q[2] - p[2])
+			points = append(points, mgl32.Vec2{p[0] + (q[0]-p[0])*f, p[1] + (q[1]-p[1])*f})
+			edges = append(edges, undirected(c[i], c[(i+1)%3]))
+		}
+
+		if len(points) != 2 {
+			continue
+		}
+
+		// Outward facets keep the material on the
+		// left, making outer loops counter-clockwise.
+		segment := sliceSegment{edges[0], edges[1], points[0], points[1]}
+		n := windingNormal(triangle)
+		d := points[1].Sub(points[0])
+		if d[1]*n[0]-d[0]*n[1] < 0 {
+			segment.from, segment.to = segment.to, segment.from
+			segment.a, segment.b = segment.b, segment.a
+		}
+
+		segments = append(segments, segment)
+	}
+
+	starts := make(map[[2]uint32]int, len(segments))
+	for i, segment := range segments {
+		starts[segment.from] = i
+	}
+
+	layer := Layer{Z: z}
+	used := make([]bool, len(segments))
+
+	for first := range segments {
+		if used[first] {
+			continue
+		}
+
+		var loop Polygon
+		closed := false
+		for i, ok := first, true; ok && !used[i]; i, ok = starts[segments[i].to] {
+			used[i] = true
+			loop = append(loop, segments[i].a)
+			closed = segments[i].to == segments[first].from
+		}
+
+		if closed && len(loop) >= 3 {
+			layer.Polygons = append(layer.Polygons, loop)
+		}
+	}
+
+	layer.orient()
+	return layer
+}
+
+// orient makes loops nested an even number of
+// times outer (counter-clockwise) and the rest
+// holes, whatever the winding of the facets.
+func (l Layer) orient() {
+	for i, p := range l.Polygons {
+		depth := 0
+		for j, q := range l.Polygons {
+			if i != j && q.Contains(p[0]) {
+				depth++
+			}
+		}
+
+		if (depth%2 == 1) != p.Hole() {
+			p.Reverse()
+		}
+	}
+}
+
+// WriteSVG
+// Draws the layer as one even-odd filled path,
+// in a view box of the XY extent of bounds so
+// every layer of a solid lines up. Y points up
+// as in the model.
+func (l Layer) WriteSVG(w io.Writer, bounds Bounds) error {
+	size := bounds.Size()
+
+	if _, err := fmt.Fprintf(w,
+		"<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%gmm\" height=\"%gmm\" viewBox=\"%g %g %g %g\">\n"+
+			"<!-- z = %g -->\n<path fill=\"black\" fill-rule=\"evenodd\" d=\"",
+		size[0], size[1], bounds.Min[0], -bounds.Max[1], size[0], size[1], l.Z,
+	); err != nil {
+		return err
+	}
+
+	for _, p := range l.Polygons {
+		for i, pt := range p {
+			command := "L"
+			if i == 0 {
+				command = "M"
+			}
+
+			if _, err := fmt.Fprintf(w, "%s%g %g ", command, pt[0], -pt[1]); err != nil {
+				return err
+			}
+		}
+
+		if _, err := io.WriteString(w, "Z "); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "\"/>\n</svg>\n")
+	return err
+}
+
+// SaveSVGLayers
+// Writes each layer to dir as layer-0001.svg
+// onwards, sharing the view box of bounds.
+func SaveSVGLayers(dir string, layers []Layer, bounds Bounds) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for i, layer := range layers {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("layer-%04d.svg", i+1)))
+		if err != nil {
+			return err
+		}
+
+		err = layer.WriteSVG(f, bounds)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var (
+	layerOuterColor = mgl32.Vec4{0.1, 0.4, 0.9, 1}
+	layerHoleColor  = mgl32.Vec4{0.9, 0.5, 0.1, 1}
+)
+
+// Lines
+// The loops as line pairs in the interleaved
+// vertex layout, drawn at the layer height;
+// outer loops blue, holes orange.
+func (l Layer) Lines() (lines []float32) {
+	for _, p := range l.Polygons {
+		c := layerOuterColor
+		if p.Hole() {
+			c = layerHoleColor
+		}
+
+		for i, a := range p {
+			b := p[(i+1)%len(p)]
+			for _, pt := range []mgl32.Vec2{a, b} {
+				lines = append(lines,
+					pt[0], pt[1], l.Z,
+					0, 0, 0,
+					0, 0,
+					c[0], c[1], c[2], c[3],
+				)
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestSliceAt(t *testing.T) {
+	// A frame with a post standing in its hole:
+	// outer loop, hole, and an island again.
+	frame := MergeSTL([]*STL{
+		prism(square(0, 20), square(6, 14), 1),
+		prism(square(8, 12), nil, 1),
+	})
+
+	inside := prism(square(0, 20), square(6, 14), 1)
+	for i := range inside.Triangles {
+		flipFacet(&inside.Triangles[i])
+	}
+
+	for _, test := range []struct {
+		name  string
+		solid *STL
+		areas []float32
+	}{
+		{"cube", prism(square(0, 10), nil, 1), []float32{100}},
+		{"frame and post", frame, []float32{400, -64, 16}},
+		{"inside out", inside, []float32{400, -64}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			layer := test.solid.SliceAt(0.5)
+			if layer.Z != 0.5 {
+				t.Errorf("z %v", layer.Z)
+			}
+
+			got := make([]float32, len(layer.Polygons))
+			for i, p := range layer.Polygons {
+				got[i] = p.Area()
+			}
+
+			if len(got) != len(test.areas) {
+				t.Fatalf("areas %v, want %v", got, test.areas)
+			}
+
+			// Loops come in no set order.
+			for _, want := range test.areas {
+				found := false
+				for _, area := range got {
+					found = found || mgl32.FloatEqualThreshold(area, want, 1e-3)
+				}
+				if !found {
+					t.Errorf("areas %v, want %v", got, test.areas)
+				}
+			}
+		})
+	}
+}
+
+func TestSlice(t *testing.T) {
+	layers := prism(square(0, 10), nil, 1).Slice(0.2)
+	if len(layers) != 5 {
+		t.Fatalf("%d layers, want 5", len(layers))
+	}
+
+	for i, layer := range layers {
+		if want := 0.1 + 0.2*float32(i); math.Abs(float64(layer.Z-want)) > 1e-5 {
+			t.Errorf("layer %d at z %v, want %v", i, layer.Z, want)
+		}
+		if len(layer.Polygons) != 1 {
+			t.Errorf("layer %d: %d loops", i, len(layer.Polygons))
+		}
+	}
+
+	// An open mesh has no closed loops.
+	open := prism(square(0, 10), nil, 1)
+	open.Triangles = open.Triangles[2:]
+	if layer := open.SliceAt(0.5); len(layer.Polygons) != 0 {
+		t.Errorf("open mesh gave %d loops", len(layer.Polygons))
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	p := square(0, 2)
+	if p.Area() != 4 || p.Hole() {
+		t.Errorf("area %v, hole %v", p.Area(), p.Hole())
+	}
+
+	if !p.Contains(mgl32.Vec2{1, 1}) || p.Contains(mgl32.Vec2{3, 1}) {
+		t.Error("Contains")
+	}
+
+	p.Reverse()
+	if p.Area() != -4 || !p.Hole() {
+		t.Errorf("reversed: area %v, hole %v", p.Area(), p.Hole())
+	}
+}
+
+func TestLayerWriteSVG(t *testing.T) {
+	s := prism(square(0, 20), square(6, 14), 1)
+	var buf bytes.Buffer
+	if err := s.SliceAt(0.5).WriteSVG(&buf, s.Bounds()); err != nil {
+		t.Fatal(err)
+	}
+
+	svg := buf.String()
+	if !strings.Contains(svg, `viewBox="0 -20 20 20"`) || strings.Count(svg, "Z ") != 2 {
+		t.Errorf("unexpected SVG:\n%s", svg)
+	}
+}
+
+func TestOffset(t *testing.T) {
+	// A 10×10 block with a 1 mm tab along its
+	// bottom edge, running out to x = 20.
+	tab := Polygon{{0, 0}, {20, 0}, {20, 1}, {10, 1}, {10, 10}, {0, 10}}
+
+	for _, test := range []struct {
+		name     string
+		polygons []Polygon
+		d        float32
+		want     []Polygon
+	}{
+		{"square", []Polygon{square(0, 1)}, 0.3, []Polygon{square(0.3, 0.7)}},
+		{"square collapsed", []Polygon{square(0, 1)}, 0.9, nil},
+		{"square far past its centre", []Polygon{square(0, 1)}, 3, nil},
+		{"hole grows", []Polygon{{{0, 0}, {0, 1}, {1, 1}, {1, 0}}}, 3, []Polygon{{{-3, -3}, {-3, 4}, {4, 4}, {4, -3}}}},
+		{"tab kept", []Polygon{tab}, 0.3, []Polygon{{{0.3, 0.3}, {19.7, 0.3}, {19.7, 0.7}, {9.7, 0.7}, {9.7, 9.7}, {0.3, 9.7}}}},
+		{"tab dropped", []Polygon{tab}, 0.9, []Polygon{{{0.9, 0.9}, {9.1, 0.9}, {9.1, 9.1}, {0.9, 9.1}}}},
+	} {
+		got := Offset(test.polygons, test.d)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+			continue
+		}
+
+		for i, p := range test.want {
+			same := len(got[i]) == len(p)
+			for j := 0; same && j < len(p); j++ {
+				same = got[i][j].ApproxEqualThreshold(p[j], 1e-5)
+			}
+			if !same {
+				t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+			}
+		}
+	}
+}
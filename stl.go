@@ -1,36 +1,53 @@
 package main
 
-import (
-	"fmt"
-	"path/filepath"
-
-	"github.com/hschendel/stl"
-)
+import "github.com/hschendel/stl"
 
 type STL struct {
 	stl.Solid
+
+	// modified is set by any edit to the
+	// geometry, so facet normals are
+	// recomputed instead of trusted on save.
+	modified bool
 }
 
+// OpenSTL
+// Reads an STL file, panicking on failure;
+// use LoadSTL to handle errors instead.
 func OpenSTL(file string) *STL {
-	file, err := filepath.Abs(file)
-	if err != nil {
-		panic(err)
-	}
-	fmt.Println(file)
-	solid, err := stl.ReadFile(file)
+	s, err := LoadSTL(file)
 	if err != nil {
 		panic(err)
 	}
 
-	return &STL{*solid}
+	return s
 }
 
-func (s *STL) Vertices() (vertices []float32) {
-	for _, triangle := range s.Triangles {
-		for _, vertex := range triangle.Vertices {
-			vertices = append(vertices, append(vertex[:], 0, 0)...)
+// Scale
+// Scales every vertex about the origin
+// and marks the solid as modified.
+func (s *STL) Scale(factor float64) {
+	s.Solid.Scale(factor)
+	s.modified = true
+}
+
+// Vertices
+// Flattens the solid into the interleaved
+// vertex layout (see vertexStride), carrying
+// each facet normal and colour on its corners.
+func (s *STL) Vertices() []float32 {
+	colors := s.FacetColors()
+
+	vertices := make([]float32, 0, len(s.Triangles)*3*vertexFloats)
+	for i, triangle := range s.Triangles {
+		color := white
+		if colors != nil {
+			color = colors[i]
 		}
+
+		triangle.Normal = s.facetNormal(triangle)
+		vertices = appendFacet(vertices, triangle, color)
 	}
 
-	return
+	return vertices
 }
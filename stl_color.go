@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// STLColorConvention is the way a binary STL
+// stores colour in the 16 bit attribute word
+// of its facets.
+type STLColorConvention int
+
+const (
+	STLColorNone STLColorConvention = iota
+
+	// STLColorVisCAM (also SolidView): bit 15 set
+	// marks a valid colour, blue in bits 0-4,
+	// green in 5-9 and red in 10-14.
+	STLColorVisCAM
+
+	// STLColorMaterialise (Magics): red in bits
+	// 0-4, green in 5-9, blue in 10-14; bit 15
+	// set means "use the COLOR= header default".
+	STLColorMaterialise
+)
+
+const (
+	stlColorTag    = "COLOR="
+	stlMaterialTag = "MATERIAL="
+
+	// Materialise tags are written at the end of
+	// the header, leaving the front for the name.
+	stlColorTagAt    = stlBinaryHeaderSize - len(stlColorTag) - 4 - len(stlMaterialTag) - 12
+	stlMaterialTagAt = stlColorTagAt + len(stlColorTag) + 4
+
+	stlColorValid = 1 << 15
+)
+
+// ColorConvention
+// Detects how the facet colours are stored:
+// a COLOR= header means Materialise, any facet
+// with bit 15 set without one means VisCAM.
+func (s *STL) ColorConvention() STLColorConvention {
+	if bytes.Contains(s.BinaryHeader, []byte(stlColorTag)) {
+		return STLColorMaterialise
+	}
+
+	for _, triangle := range s.Triangles {
+		if triangle.Attributes&stlColorValid != 0 {
+			return STLColorVisCAM
+		}
+	}
+
+	return STLColorNone
+}
+
+// DefaultColor
+// Decodes the RGBA following COLOR=
+// in a Materialise header.
+func (s *STL) DefaultColor() (mgl32.Vec4, bool) {
+	data, ok := s.headerTag(stlColorTag, 4)
+	if !ok {
+		return white, false
+	}
+
+	return rgbaBytes(data), true
+}
+
+// HeaderMaterial
+// Decodes the diffuse, specular and ambient
+// RGBA following MATERIAL= in a Materialise
+// header into a Material.
+func (s *STL) HeaderMaterial() (*Material, bool) {
+	data, ok := s.headerTag(stlMaterialTag, 12)
+	if !ok {
+		return nil, false
+	}
+
+	return &Material{
+		Name:      stlMaterialTag[:len(stlMaterialTag)-1],
+		Diffuse:   rgbaBytes(data[0:4]).Vec3(),
+		Specular:  rgbaBytes(data[4:8]).Vec3(),
+		Shininess: DefaultMaterial.Shininess,
+	}, true
+}
+
+// FacetColors
+// Decodes the colour of every facet under the
+// detected convention; facets without their
+// own colour get the DefaultColor(). Returns
+// nil when the solid carries no colour.
+func (s *STL) FacetColors() []mgl32.Vec4 {
+	convention := s.ColorConvention()
+	if convention == STLColorNone {
+		return nil
+	}
+
+	fallback, _ := s.DefaultColor()
+	colors := make([]mgl32.Vec4, len(s.Triangles))
+
+	for i, triangle := range s.Triangles {
+		colors[i] = decodeFacetColor(triangle.Attributes, convention, fallback)
+	}
+
+	return colors
+}
+
+func decodeFacetColor(
+	a uint16, convention STLColorConvention,
+	fallback mgl32.Vec4,
+) mgl32.Vec4 {
+	low := float32(a&0x1f) / 31
+	mid := float32(a>>5&0x1f) / 31
+	high := float32(a>>10&0x1f) / 31
+
+	switch {
+	case convention == STLColorVisCAM && a&stlColorValid != 0:
+		return mgl32.Vec4{high, mid, low, 1}
+	case convention == STLColorMaterialise && a&stlColorValid == 0:
+		return mgl32.Vec4{low, mid, high, 1}
+	}
+
+	return fallback
+}
+
+// SetFacetColors
+// Encodes one colour per facet into the attribute
+// words. For Materialise, facets matching the
+// DefaultColor() defer to the header instead.
+func (s *STL) SetFacetColors(colors []mgl32.Vec4, convention STLColorConvention) {
+	fallback, hasFallback := s.DefaultColor()
+
+	for i := range s.Triangles {
+		if i >= len(colors) {
+			break
+		}
+
+		r, g, b := rgb555(colors[i])
+
+		switch convention {
+		case STLColorVisCAM:
+			s.Triangles[i].Attributes = stlColorValid | r<<10 | g<<5 | b
+		case STLColorMaterialise:
+			if hasFallback && colors[i] == fallback {
+				s.Triangles[i].Attributes = stlColorValid
+			} else {
+				s.Triangles[i].Attributes = b<<10 | g<<5 | r
+			}
+		default:
+			s.Triangles[i].Attributes = 0
+		}
+	}
+}
+
+// SetDefaultColor
+// Writes the Materialise COLOR= entry.
+func (s *STL) SetDefaultColor(color mgl32.Vec4) {
+	data := rgbaToBytes(color)
+	s.setHeaderTag(stlColorTag, stlColorTagAt, data[:])
+}
+
+// SetHeaderMaterial
+// Writes the Materialise MATERIAL= entry from
+// the colours of a Material; the ambient colour,
+// which Material lacks, repeats the diffuse.
+func (s *STL) SetHeaderMaterial(m *Material) {
+	diffuse := rgbaToBytes(m.Diffuse.Vec4(1))
+	specular := rgbaToBytes(m.Specular.Vec4(1))
+	ambient := diffuse
+
+	data := append(append(diffuse[:], specular[:]...), ambient[:]...)
+	s.setHeaderTag(stlMaterialTag, stlMaterialTagAt, data)
+}
+
+func (s *STL) headerTag(tag string, size int) ([]byte, bool) {
+	i := bytes.Index(s.BinaryHeader, []byte(tag))
+	if i < 0 || i+len(tag)+size > len(s.BinaryHeader) {
+		return nil, false
+	}
+
+	i += len(tag)
+	return s.BinaryHeader[i : i+size], true
+}
+
+// setHeaderTag overwrites an existing tag in
+// place, or writes it at its slot at the end
+// of the header, over any name text there.
+func (s *STL) setHeaderTag(tag string, at int, data []byte) {
+	if len(s.BinaryHeader) != stlBinaryHeaderSize {
+		header := make([]byte, stlBinaryHeaderSize)
+		copy(header[:stlColorTagAt], s.Name)
+		s.BinaryHeader = header
+	}
+
+	if i := bytes.Index(s.BinaryHeader, []byte(tag)); i >= 0 && i+len(tag)+len(data) <= len(s.BinaryHeader) {
+		at = i
+	}
+
+	copy(s.BinaryHeader[at:], tag)
+	copy(s.BinaryHeader[at+len(tag):], data)
+}
+
+// renamedHeader is a fresh header for a solid
+// called name carrying the COLOR= and MATERIAL=
+// tags of this one, or nil when it has neither,
+// so the name alone is written.
+func (s *STL) renamedHeader(name string) []byte {
+	color, hasColor := s.headerTag(stlColorTag, 4)
+	material, hasMaterial := s.headerTag(stlMaterialTag, 12)
+	if !hasColor && !hasMaterial {
+		return nil
+	}
+
+	renamed := &STL{}
+	renamed.Name = name
+	if hasColor {
+		renamed.setHeaderTag(stlColorTag, stlColorTagAt, color)
+	}
+	if hasMaterial {
+		renamed.setHeaderTag(stlMaterialTag, stlMaterialTagAt, material)
+	}
+
+	return renamed.BinaryHeader
+}
+
+func rgb555(c mgl32.Vec4) (r, g, b uint16) {
+	channel := func(f float32) uint16 {
+		return uint16(mgl32.Clamp(f, 0, 1)*31 + 0.5)
+	}
+
+	return channel(c[0]), channel(c[1]), channel(c[2])
+}
+
+func rgbaBytes(b []byte) mgl32.Vec4 {
+	return mgl32.Vec4{
+		float32(b[0]) / 255, float32(b[1]) / 255,
+		float32(b[2]) / 255, float32(b[3]) / 255,
+	}
+}
+
+func rgbaToBytes(c mgl32.Vec4) (b [4]byte) {
+	for i, f := range c {
+		b[i] = byte(mgl32.Clamp(f, 0, 1)*255 + 0.5)
+	}
+
+	return
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/hschendel/stl"
+)
+
+// Kinds of STL read failure, matched with
+// errors.Is() against an *STLError.
+var (
+	ErrSTLNotFound      = errors.New("file not found")
+	ErrSTLTruncated     = errors.New("truncated binary STL")
+	ErrSTLCountMismatch = errors.New("triangle count mismatch")
+	ErrSTLNonFinite     = errors.New("non-finite coordinate")
+	ErrSTLSyntax        = errors.New("malformed ASCII STL")
+)
+
+// STLError locates a failure to read an STL:
+// Offset is the byte offset for binary files,
+// Line the line number for ASCII ones. Errors
+// opening the file have neither.
+type STLError struct {
+	Kind   error
+	Path   string
+	Offset int64
+	Line   int
+
+	// Err is the underlying cause, if any.
+	Err error
+}
+
+func (e *STLError) Error() string {
+	msg := "stl: "
+	if e.Path != "" {
+		msg += e.Path + ": "
+	}
+
+	switch {
+	case e.Line > 0:
+		msg += fmt.Sprintf("line %d: ", e.Line)
+	case e.Kind == ErrSTLTruncated, e.Kind == ErrSTLCountMismatch, e.Kind == ErrSTLNonFinite:
+		msg += fmt.Sprintf("offset %d: ", e.Offset)
+	}
+
+	msg += e.Kind.Error()
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *STLError) Unwrap() error {
+	return e.Kind
+}
+
+// Is
+// Matches the Kind as well as the cause, so
+// errors.Is(err, os.ErrNotExist) also holds.
+func (e *STLError) Is(target error) bool {
+	return target == e.Kind || e.Err != nil && errors.Is(e.Err, target)
+}
+
+// LoadSTL
+// Reads an ASCII or binary STL file.
+func LoadSTL(path string) (*STL, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		kind := err
+		if errors.Is(err, os.ErrNotExist) {
+			kind = ErrSTLNotFound
+		}
+		return nil, &STLError{Kind: kind, Path: path, Err: err}
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, &STLError{Kind: err, Path: path, Err: err}
+	}
+
+	s, err := readSTL(f, info.Size())
+	if e, ok := err.(*STLError); ok {
+		e.Path = path
+	}
+
+	return s, err
+}
+
+// ReadSTL
+// Reads an ASCII or binary STL. When r can
+// seek, its length settles binary files whose
+// header starts with "solid".
+func ReadSTL(r io.Reader) (*STL, error) {
+	size := int64(-1)
+	if seeker, ok := r.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			if end, err := seeker.Seek(0, io.SeekEnd); err == nil {
+				size = end - start
+			}
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return readSTL(r, size)
+}
+
+func readSTL(r io.Reader, size int64) (*STL, error) {
+	br := bufio.NewReaderSize(r, 1<<16)
+
+	head, err := br.Peek(stlBinaryHeaderSize + 4)
+	if isASCIISTL(head, size) {
+		return readASCIISTL(br)
+	}
+
+	if err != nil {
+		return nil, &STLError{Kind: ErrSTLTruncated, Offset: int64(len(head)), Err: err}
+	}
+
+	return readBinarySTL(br)
+}
+
+func readBinarySTL(r io.Reader) (*STL, error) {
+	header := make([]byte, stlBinaryHeaderSize+4)
+	if n, err := io.ReadFull(r, header); err != nil {
+		return nil, &STLError{Kind: ErrSTLTruncated, Offset: int64(n), Err: err}
+	}
+
+	name := header[:stlBinaryHeaderSize]
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+
+	count := binary.LittleEndian.Uint32(header[stlBinaryHeaderSize:])
+	s := &STL{Solid: stl.Solid{
+		Name:         strings.TrimSpace(string(name)),
+		BinaryHeader: header[:stlBinaryHeaderSize],
+		Triangles:    make([]stl.Triangle, 0, stlPresize(count)),
+	}}
+
+	offset := int64(len(header))
+	facet := make([]byte, 50)
+
+	for i := uint32(0); i < count; i++ {
+		n, err := io.ReadFull(r, facet)
+		if err == io.EOF {
+			return nil, &STLError{
+				Kind:   ErrSTLCountMismatch,
+				Offset: offset,
+				Err:    fmt.Errorf("header claims %d triangles, file holds %d", count, i),
+			}
+		}
+
+		if err != nil {
+			return nil, &STLError{Kind: ErrSTLTruncated, Offset: offset + int64(n), Err: err}
+		}
+
+		triangle, bad := decodeBinaryFacet(facet)
+		if bad >= 0 {
+			return nil, &STLError{Kind: ErrSTLNonFinite, Offset: offset + int64(bad)}
+		}
+
+		s.Triangles = append(s.Triangles, triangle)
+		offset += 50
+	}
+
+	// Whole facets past the count mean the
+	// header is wrong; shorter trailing
+	// junk is tolerated like other readers.
+	var extra int64
+	for {
+		n, err := io.ReadFull(r, facet)
+		if n == len(facet) {
+			extra++
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if extra > 0 {
+		return nil, &STLError{
+			Kind:   ErrSTLCountMismatch,
+			Offset: offset,
+			Err:    fmt.Errorf("header claims %d triangles, file holds %d", count, int64(count)+extra),
+		}
+	}
+
+	return s, nil
+}
+
+// decodeBinaryFacet decodes the 50 bytes of a binary
+// facet; bad is the offset of the first non-finite
+// vertex coordinate within it, or -1.
+func decodeBinaryFacet(buf []byte) (triangle stl.Triangle, bad int) {
+	bad = -1
+
+	for i := range triangle.Normal {
+		triangle.Normal[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+
+	for j := range triangle.Vertices {
+		for i := range triangle.Vertices[j] {
+			at := 12 + j*12 + i*4
+			v := math.Float32frombits(binary.LittleEndian.Uint32(buf[at:]))
+			if bad < 0 && !isFinite32(v) {
+				bad = at
+			}
+			triangle.Vertices[j][i] = v
+		}
+	}
+
+	triangle.Attributes = binary.LittleEndian.Uint16(buf[48:])
+	return
+}
+
+func readASCIISTL(r io.Reader) (*STL, error) {
+	s := &STL{Solid: stl.Solid{IsAscii: true}}
+	scanner := bufio.NewScanner(r)
+
+	var triangle stl.Triangle
+	corner, line := -1, 0
+
+	syntax := func(format string, args ...interface{}) error {
+		return &STLError{Kind: ErrSTLSyntax, Line: line, Err: fmt.Errorf(format, args...)}
+	}
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "solid":
+			s.Name = strings.TrimSpace(strings.TrimPrefix(text, "solid"))
+
+		case "facet":
+			if corner >= 0 {
+				return nil, syntax("facet inside a facet")
+			}
+
+			if len(fields) != 5 || fields[1] != "normal" {
+				return nil, syntax("expected \"facet normal x y z\"")
+			}
+
+			triangle, corner = stl.Triangle{}, 0
+
+			var err error
+			if triangle.Normal, err = parseSTLVec3(fields[2:]); err != nil {
+				return nil, syntax("%v", err)
+			}
+
+		case "vertex":
+			if corner < 0 || corner > 2 || len(fields) != 4 {
+				return nil, syntax("unexpected %q", text)
+			}
+
+			v, err := parseSTLVec3(fields[1:])
+			if err != nil {
+				return nil, syntax("%v", err)
+			}
+
+			for _, f := range v {
+				if !isFinite32(f) {
+					return nil, &STLError{Kind: ErrSTLNonFinite, Line: line}
+				}
+			}
+
+			triangle.Vertices[corner] = v
+			corner++
+
+		case "endfacet":
+			if corner != 3 {
+				return nil, syntax("facet has %d vertices", corner)
+			}
+
+			s.Triangles = append(s.Triangles, triangle)
+			corner = -1
+
+		case "outer", "endloop":
+
+		case "endsolid":
+			if corner >= 0 {
+				return nil, syntax("endsolid inside a facet")
+			}
+			return s, nil
+
+		default:
+			return nil, syntax("unknown keyword %q", fields[0])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, &STLError{Kind: ErrSTLSyntax, Line: line, Err: err}
+	}
+
+	if corner >= 0 {
+		return nil, syntax("unexpected end of file inside a facet")
+	}
+
+	return s, nil
+}
+
+// stlPresize caps the capacity taken from an
+// untrusted header, so a corrupt count cannot
+// allocate gigabytes before reading fails.
+func stlPresize(count uint32) int {
+	const max = 1 << 20
+	if count > max {
+		return max
+	}
+
+	return int(count)
+}
+
+func isFinite32(f float32) bool {
+	return !math.IsNaN(float64(f)) && !math.IsInf(float64(f), 0)
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hschendel/stl"
+)
+
+// stlStreamChunk is the number of binary
+// facets decoded between progress reports
+// and cancellation checks.
+const stlStreamChunk = 16384
+
+// stlASCIIFacetBytes is roughly the size of an
+// ASCII facet, used to pre-size the buffer.
+const stlASCIIFacetBytes = 250
+
+// STLProgress reports how far a streaming
+// load has got. Total is only known up
+// front for binary files, Size when the
+// length of the input is known.
+type STLProgress struct {
+	Triangles, Total int
+	Bytes, Size      int64
+}
+
+// Fraction
+// Completion between 0 and 1,
+// or -1 when it cannot be told.
+func (p STLProgress) Fraction() float64 {
+	switch {
+	case p.Total > 0:
+		return float64(p.Triangles) / float64(p.Total)
+	case p.Size > 0:
+		return float64(p.Bytes) / float64(p.Size)
+	}
+
+	return -1
+}
+
+// StreamSTLFile
+// Streams an STL file straight into the
+// interleaved vertex layout; see StreamSTL.
+func StreamSTLFile(
+	ctx context.Context, file string,
+	progress func(STLProgress),
+) ([]float32, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return StreamSTL(ctx, f, info.Size(), progress)
+}
+
+// StreamSTL
+// Decodes an STL without building an STL value:
+// facets go straight into a vertex buffer, in the
+// layout of *STL.Vertices(), that is pre-sized from
+// the binary triangle count. progress, if not nil,
+// is called after every chunk and may forward to a
+// channel; ctx cancels the load between chunks.
+// size is the input length, or -1 if unknown.
+func StreamSTL(
+	ctx context.Context, r io.Reader, size int64,
+	progress func(STLProgress),
+) ([]float32, error) {
+	if progress == nil {
+		progress = func(STLProgress) {}
+	}
+
+	br := bufio.NewReaderSize(r, 1<<16)
+
+	head, _ := br.Peek(stlBinaryHeaderSize + 4)
+	if isASCIISTL(head, size) {
+		return streamASCIISTL(ctx, br, size, progress)
+	}
+
+	return streamBinarySTL(ctx, br, size, progress)
+}
+
+// isASCIISTL tells the encodings apart. Binary
+// headers may start with "solid" too, so the
+// triangle count is checked against the size;
+// unsized input falls back to looking for text.
+func isASCIISTL(head []byte, size int64) bool {
+	if !bytes.HasPrefix(head, []byte("solid")) {
+		return false
+	}
+
+	if len(head) < stlBinaryHeaderSize+4 {
+		return true
+	}
+
+	if size >= 0 {
+		count := int64(binary.LittleEndian.Uint32(head[stlBinaryHeaderSize:]))
+		return stlBinaryHeaderSize+4+count*50 != size
+	}
+
+	return bytes.IndexByte(head, 0) < 0
+}
+
+func streamBinarySTL(
+	ctx context.Context, r io.Reader, size int64,
+	progress func(STLProgress),
+) ([]float32, error) {
+	header := make([]byte, stlBinaryHeaderSize+4)
+	if n, err := io.ReadFull(r, header); err != nil {
+		return nil, &STLError{Kind: ErrSTLTruncated, Offset: int64(n), Err: err}
+	}
+
+	total := int(binary.LittleEndian.Uint32(header[stlBinaryHeaderSize:]))
+	if size >= 0 && int64(len(header))+int64(total)*50 > size {
+		return nil, &STLError{
+			Kind:   ErrSTLCountMismatch,
+			Offset: stlBinaryHeaderSize,
+			Err: fmt.Errorf("header claims %d triangles, file holds %d",
+				total, (size-int64(len(header)))/50),
+		}
+	}
+
+	// Facet colours follow the same rules
+	// as FacetColors(), decided per facet.
+	solid := &STL{Solid: stl.Solid{BinaryHeader: header[:stlBinaryHeaderSize]}}
+	convention := STLColorVisCAM
+	if solid.ColorConvention() == STLColorMaterialise {
+		convention = STLColorMaterialise
+	}
+	fallback, _ := solid.DefaultColor()
+
+	vertices := make([]float32, 0, stlPresize(uint32(total))*3*vertexFloats)
+	chunk := make([]byte, stlStreamChunk*50)
+	status := STLProgress{Total: total, Bytes: int64(len(header)), Size: size}
+
+	for status.Triangles < total {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n := total - status.Triangles
+		if n > stlStreamChunk {
+			n = stlStreamChunk
+		}
+
+		buf := chunk[:n*50]
+		if read, err := io.ReadFull(r, buf); err != nil {
+			return nil, &STLError{Kind: ErrSTLTruncated, Offset: status.Bytes + int64(read), Err: err}
+		}
+
+		for at := status.Bytes; len(buf) > 0; at += 50 {
+			triangle, bad := decodeBinaryFacet(buf)
+			if bad >= 0 {
+				return nil, &STLError{Kind: ErrSTLNonFinite, Offset: at + int64(bad)}
+			}
+
+			color := decodeFacetColor(triangle.Attributes, convention, fallback)
+			vertices = appendFacet(vertices, triangle, color)
+			buf = buf[50:]
+		}
+
+		status.Triangles += n
+		status.Bytes += int64(n) * 50
+		progress(status)
+	}
+
+	return vertices, nil
+}
+
+func streamASCIISTL(
+	ctx context.Context, r io.Reader, size int64,
+	progress func(STLProgress),
+) ([]float32, error) {
+	var vertices []float32
+	if size > 0 {
+		vertices = make([]float32, 0, int(size/stlASCIIFacetBytes)*3*vertexFloats)
+	}
+
+	status := STLProgress{Size: size}
+	counter := &countingReader{Reader: r}
+	scanner := bufio.NewScanner(counter)
+
+	var triangle stl.Triangle
+	corner, line := -1, 0
+
+	for scanner.Scan() {
+		line++
+		status.Bytes = counter.n
+		text := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var err error
+		switch fields[0] {
+		case "solid", "outer", "endloop":
+
+		case "facet":
+			if corner >= 0 {
+				err = fmt.Errorf("facet inside a facet")
+				break
+			}
+
+			if len(fields) != 5 || fields[1] != "normal" {
+				err = fmt.Errorf("expected \"facet normal x y z\"")
+				break
+			}
+
+			triangle, corner = stl.Triangle{}, 0
+			triangle.Normal, err = parseSTLVec3(fields[2:])
+
+		case "vertex":
+			if corner < 0 || corner > 2 || len(fields) != 4 {
+				err = fmt.Errorf("unexpected %q", text)
+				break
+			}
+
+			triangle.Vertices[corner], err = parseSTLVec3(fields[1:])
+			for _, f := range triangle.Vertices[corner] {
+				if err == nil && !isFinite32(f) {
+					return nil, &STLError{Kind: ErrSTLNonFinite, Line: line}
+				}
+			}
+			corner++
+
+		case "endfacet":
+			if corner != 3 {
+				err = fmt.Errorf("facet has %d vertices", corner)
+				break
+			}
+			vertices = appendFacet(vertices, triangle, white)
+			status.Triangles++
+			corner = -1
+
+			if status.Triangles%stlStreamChunk == 0 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				progress(status)
+			}
+
+		case "endsolid":
+			if corner >= 0 {
+				err = fmt.Errorf("endsolid inside a facet")
+			}
+
+		default:
+			err = fmt.Errorf("unknown keyword %q", fields[0])
+		}
+
+		if err != nil {
+			return nil, &STLError{Kind: ErrSTLSyntax, Line: line, Err: err}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if corner >= 0 {
+		return nil, &STLError{Kind: ErrSTLSyntax, Line: line, Err: fmt.Errorf("unexpected end of file inside a facet")}
+	}
+
+	status.Bytes = counter.n
+	progress(status)
+	return vertices, nil
+}
+
+// countingReader counts the bytes read through
+// it; the scanner reads ahead of its lines, but
+// whatever the line endings it reaches the size.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// appendFacet writes the three corners of a
+// facet in the layout of *STL.Vertices().
+func appendFacet(vertices []float32, triangle stl.Triangle, color mgl32.Vec4) []float32 {
+	normal := triangle.Normal
+	if normal == (stl.Vec3{}) {
+		normal = windingNormal(triangle)
+	}
+
+	for _, vertex := range triangle.Vertices {
+		vertices = append(vertices,
+			vertex[0], vertex[1], vertex[2],
+			normal[0], normal[1], normal[2],
+			0, 0,
+			color[0], color[1], color[2], color[3],
+		)
+	}
+
+	return vertices
+}
+
+func parseSTLVec3(fields []string) (v stl.Vec3, err error) {
+	for i := range v {
+		f, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return v, err
+		}
+		v[i] = float32(f)
+	}
+
+	return
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStreamSTL(t *testing.T) {
+	cube := prism(square(0, 10), nil, 10)
+
+	for _, format := range []string{"ascii", "ascii crlf", "binary"} {
+		var buf bytes.Buffer
+		write := cube.WriteBinary
+		if format != "binary" {
+			write = cube.WriteASCII
+		}
+		if err := write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if format == "ascii crlf" {
+			crlf := bytes.ReplaceAll(buf.Bytes(), []byte("\n"), []byte("\r\n"))
+			buf.Reset()
+			buf.Write(crlf)
+		}
+
+		var last STLProgress
+		vertices, err := StreamSTL(context.Background(), bytes.NewReader(buf.Bytes()), int64(buf.Len()),
+			func(p STLProgress) { last = p })
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if want := len(cube.Triangles) * 3 * vertexFloats; len(vertices) != want {
+			t.Errorf("%s: %d floats, want %d", format, len(vertices), want)
+		}
+		if last.Fraction() != 1 {
+			t.Errorf("%s: progress ends at %v", format, last.Fraction())
+		}
+	}
+}
+
+func TestStreamSTLSyntax(t *testing.T) {
+	const facet = "facet normal 0 0 1\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nvertex 0 1 0\nendloop\nendfacet\n"
+
+	for _, text := range []string{
+		"solid a\nfacet\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nvertex 0 1 0\nendloop\nendfacet\nendsolid a\n",
+		"solid a\nfacet normal 0 0\nendfacet\nendsolid a\n",
+		"solid a\nvertex 0 0 0\nendsolid a\n",
+		"solid a\nfacet normal 0 0 1\n" + facet + "endsolid a\n",
+		"solid a\nfacet normal 0 0 1\nouter loop\nvertex 0 0 0\nendsolid a\n",
+		"solid a\n" + facet + "bogus\nendsolid a\n",
+		"solid a\nfacet normal 0 0 1\nouter loop\nvertex 0 0 0\n",
+	} {
+		_, err := StreamSTL(context.Background(), strings.NewReader(text), int64(len(text)), nil)
+		if !errors.Is(err, ErrSTLSyntax) {
+			t.Errorf("%q: got %v, want a syntax error", text, err)
+		}
+
+		if _, err := ReadSTL(strings.NewReader(text)); !errors.Is(err, ErrSTLSyntax) {
+			t.Errorf("%q: ReadSTL got %v", text, err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/hschendel/stl"
+)
+
+// STLFormat selects the encoding
+// used when saving an STL.
+type STLFormat int
+
+const (
+	// STLAuto keeps the encoding
+	// the solid was read with.
+	STLAuto STLFormat = iota
+	STLBinary
+	STLASCII
+)
+
+const stlBinaryHeaderSize = 80
+
+// SaveSTL
+// Writes the solid to path in the given format.
+func (s *STL) SaveSTL(path string, format STLFormat) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if format == STLAuto {
+		format = STLBinary
+		if s.IsAscii {
+			format = STLASCII
+		}
+	}
+
+	if format == STLASCII {
+		return s.WriteASCII(f)
+	}
+
+	return s.WriteBinary(f)
+}
+
+// WriteBinary
+// Writes the solid as little endian binary STL.
+//
+// The original 80 byte header and the per
+// facet attribute words are preserved, so an
+// unmodified binary file round-trips byte-for-byte.
+func (s *STL) WriteBinary(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, stlBinaryHeaderSize)
+	if len(s.BinaryHeader) == stlBinaryHeaderSize {
+		copy(header, s.BinaryHeader)
+	} else {
+		copy(header, s.Name)
+	}
+	bw.Write(header)
+
+	var facet [50]byte
+	binary.LittleEndian.PutUint32(facet[:4], uint32(len(s.Triangles)))
+	bw.Write(facet[:4])
+
+	for _, triangle := range s.Triangles {
+		normal := s.facetNormal(triangle)
+
+		for i, v := range normal {
+			binary.LittleEndian.PutUint32(facet[i*4:], math.Float32bits(v))
+		}
+
+		for j, vertex := range triangle.Vertices {
+			for i, v := range vertex {
+				binary.LittleEndian.PutUint32(facet[12+j*12+i*4:], math.Float32bits(v))
+			}
+		}
+
+		binary.LittleEndian.PutUint16(facet[48:], triangle.Attributes)
+		bw.Write(facet[:])
+	}
+
+	return bw.Flush()
+}
+
+// WriteASCII
+// Writes the solid as ASCII STL using the
+// layout emitted by OpenSCAD.
+//
+// Coordinates are written in the shortest form
+// that parses back to the same float32, so an
+// unmodified OpenSCAD file round-trips byte-for-byte.
+func (s *STL) WriteASCII(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	solid := "solid"
+	if s.Name != "" {
+		solid += " " + s.Name
+	}
+
+	bw.WriteString(solid + "\n")
+
+	buf := make([]byte, 0, 64)
+	for _, triangle := range s.Triangles {
+		buf = appendSTLVec3(append(buf[:0], "  facet normal"...), s.facetNormal(triangle))
+		bw.Write(append(buf, '\n'))
+		bw.WriteString("    outer loop\n")
+
+		for _, vertex := range triangle.Vertices {
+			buf = appendSTLVec3(append(buf[:0], "      vertex"...), vertex)
+			bw.Write(append(buf, '\n'))
+		}
+
+		bw.WriteString("    endloop\n")
+		bw.WriteString("  endfacet\n")
+	}
+
+	bw.WriteString("end" + solid + "\n")
+	return bw.Flush()
+}
+
+// facetNormal returns the stored normal of an
+// untouched solid, or one recomputed from the
+// winding once the geometry has been modified.
+func (s *STL) facetNormal(triangle stl.Triangle) stl.Vec3 {
+	if !s.modified {
+		return triangle.Normal
+	}
+
+	return windingNormal(triangle)
+}
+
+// windingNormal
+// Computes the unit normal implied by the
+// counter-clockwise vertex order of a facet;
+// degenerate facets yield the zero vector.
+func windingNormal(triangle stl.Triangle) (normal stl.Vec3) {
+	var a, b [3]float64
+	for i := range a {
+		a[i] = float64(triangle.Vertices[1][i] - triangle.Vertices[0][i])
+		b[i] = float64(triangle.Vertices[2][i] - triangle.Vertices[0][i])
+	}
+
+	n := [3]float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+
+	length := math.Sqrt(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])
+	if length == 0 {
+		return
+	}
+
+	for i := range n {
+		normal[i] = float32(n[i] / length)
+	}
+
+	return
+}
+
+func appendSTLVec3(buf []byte, v stl.Vec3) []byte {
+	for _, f := range v {
+		buf = append(buf, ' ')
+		buf = strconv.AppendFloat(buf, float64(f), 'g', -1, 32)
+	}
+
+	return buf
+}
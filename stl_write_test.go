@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hschendel/stl"
+)
+
+func TestSTLRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, test := range []struct {
+		name   string
+		format STLFormat
+		ascii  bool
+	}{
+		{"binary", STLBinary, false},
+		{"ascii", STLASCII, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cube := prism(square(0, 10), nil, 10)
+			cube.Name = "cube"
+
+			path := filepath.Join(dir, test.name+".stl")
+			if err := cube.SaveSTL(path, test.format); err != nil {
+				t.Fatal(err)
+			}
+
+			read, err := LoadSTL(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if read.IsAscii != test.ascii {
+				t.Errorf("IsAscii %v, want %v", read.IsAscii, test.ascii)
+			}
+			if test.ascii && read.Name != cube.Name {
+				t.Errorf("name %q, want %q", read.Name, cube.Name)
+			}
+			if len(read.Triangles) != len(cube.Triangles) {
+				t.Fatalf("%d facets, want %d", len(read.Triangles), len(cube.Triangles))
+			}
+			for i, triangle := range cube.Triangles {
+				if read.Triangles[i].Vertices != triangle.Vertices || read.Triangles[i].Normal != triangle.Normal {
+					t.Fatalf("facet %d: %+v, want %+v", i, read.Triangles[i], triangle)
+				}
+			}
+
+			// Saving what was read gives the same bytes.
+			again := filepath.Join(dir, test.name+"-again.stl")
+			if err := read.SaveSTL(again, STLAuto); err != nil {
+				t.Fatal(err)
+			}
+			if !sameFile(t, path, again) {
+				t.Error("an unmodified solid does not save back byte for byte")
+			}
+		})
+	}
+}
+
+func TestSTLModifiedNormals(t *testing.T) {
+	stale := stl.Vec3{1, 0, 0}
+
+	for _, format := range []STLFormat{STLBinary, STLASCII} {
+		for _, modify := range []bool{false, true} {
+			// Stale normals are kept while the solid is
+			// untouched and recomputed once it changes.
+			cube := prism(square(0, 10), nil, 10)
+			for i := range cube.Triangles {
+				cube.Triangles[i].Normal = stale
+			}
+			if modify {
+				cube.Rotate(90, mgl32.Vec3{0, 0, 1})
+			}
+
+			path := filepath.Join(t.TempDir(), "cube.stl")
+			if err := cube.SaveSTL(path, format); err != nil {
+				t.Fatal(err)
+			}
+
+			read, err := LoadSTL(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, triangle := range read.Triangles {
+				want := stale
+				if modify {
+					want = windingNormal(triangle)
+				}
+				if !mgl32.Vec3(triangle.Normal).ApproxEqualThreshold(mgl32.Vec3(want), 1e-6) {
+					t.Fatalf("format %d, modified %v, facet %d: normal %v, want %v",
+						format, modify, i, triangle.Normal, want)
+				}
+			}
+		}
+	}
+}
+
+func sameFile(t *testing.T, a, b string) bool {
+	x, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	y, err := os.ReadFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes.Equal(x, y)
+}
@@ -0,0 +1,200 @@
+; generated by blocked
+; layer height 0.200, width 0.450, 2 perimeters, infill 20%
+M140 S60 ; bed temperature
+M104 S210 ; nozzle temperature
+G28 ; home all axes
+M190 S60 ; wait for bed
+M109 S210 ; wait for nozzle
+G1 Z5 F3000
+G21 ; millimetres
+G90 ; absolute positions
+M83 ; relative extrusion
+;LAYER:0
+G0 Z0.200 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X114.325 Y105.675 F9000
+G1 E1.00000 F2100
+G1 X114.325 Y114.325 E0.32366 F1200
+G1 X105.675 Y114.325 E0.32366 F1200
+G1 X105.675 Y105.675 E0.32366 F1200
+G1 X114.325 Y105.675 E0.32366 F1200
+G0 X114.775 Y105.225 F9000
+G1 X114.775 Y114.775 E0.35734 F1200
+G1 X105.225 Y114.775 E0.35734 F1200
+G1 X105.225 Y105.225 E0.35734 F1200
+G1 X114.775 Y105.225 E0.35734 F1200
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X112.264 Y105.900 F9000
+G1 E1.00000 F2100
+G1 X114.100 Y107.736 E0.09716 F1200
+G1 E-1.00000 F2100
+G0 X114.100 Y110.918 F9000
+G1 E1.00000 F2100
+G1 X109.082 Y105.900 E0.26554 F1200
+G1 E-1.00000 F2100
+G0 X105.900 Y105.900 F9000
+G1 E1.00000 F2100
+G1 X114.100 Y114.100 E0.43392 F1200
+G1 E-1.00000 F2100
+G0 X110.918 Y114.100 F9000
+G1 E1.00000 F2100
+G1 X105.900 Y109.082 E0.26554 F1200
+G1 E-1.00000 F2100
+G0 X105.900 Y112.264 F9000
+G1 E1.00000 F2100
+G1 X107.736 Y114.100 E0.09716 F1200
+;LAYER:1
+G0 Z0.400 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X114.325 Y105.675 F9000
+G1 E1.00000 F2100
+G1 X114.325 Y114.325 E0.32366 F2400
+G1 X105.675 Y114.325 E0.32366 F2400
+G1 X105.675 Y105.675 E0.32366 F2400
+G1 X114.325 Y105.675 E0.32366 F2400
+G0 X114.775 Y105.225 F9000
+G1 X114.775 Y114.775 E0.35734 F2400
+G1 X105.225 Y114.775 E0.35734 F2400
+G1 X105.225 Y105.225 E0.35734 F2400
+G1 X114.775 Y105.225 E0.35734 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X111.810 Y114.100 F9000
+G1 E1.00000 F2100
+G1 X114.100 Y111.810 E0.12118 F3600
+G1 E-1.00000 F2100
+G0 X114.100 Y108.628 F9000
+G1 E1.00000 F2100
+G1 X108.628 Y114.100 E0.28956 F3600
+G1 E-1.00000 F2100
+G0 X105.900 Y113.646 F9000
+G1 E1.00000 F2100
+G1 X113.646 Y105.900 E0.40989 F3600
+G1 E-1.00000 F2100
+G0 X110.464 Y105.900 F9000
+G1 E1.00000 F2100
+G1 X105.900 Y110.464 E0.24151 F3600
+G1 E-1.00000 F2100
+G0 X105.900 Y107.282 F9000
+G1 E1.00000 F2100
+G1 X107.282 Y105.900 E0.07313 F3600
+;LAYER:2
+G0 Z0.600 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X114.325 Y105.675 F9000
+G1 E1.00000 F2100
+G1 X114.325 Y114.325 E0.32366 F2400
+G1 X105.675 Y114.325 E0.32366 F2400
+G1 X105.675 Y105.675 E0.32366 F2400
+G1 X114.325 Y105.675 E0.32366 F2400
+G0 X114.775 Y105.225 F9000
+G1 X114.775 Y114.775 E0.35734 F2400
+G1 X105.225 Y114.775 E0.35734 F2400
+G1 X105.225 Y105.225 E0.35734 F2400
+G1 X114.775 Y105.225 E0.35734 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X112.264 Y105.900 F9000
+G1 E1.00000 F2100
+G1 X114.100 Y107.736 E0.09716 F3600
+G1 E-1.00000 F2100
+G0 X114.100 Y110.918 F9000
+G1 E1.00000 F2100
+G1 X109.082 Y105.900 E0.26554 F3600
+G1 E-1.00000 F2100
+G0 X105.900 Y105.900 F9000
+G1 E1.00000 F2100
+G1 X114.100 Y114.100 E0.43392 F3600
+G1 E-1.00000 F2100
+G0 X110.918 Y114.100 F9000
+G1 E1.00000 F2100
+G1 X105.900 Y109.082 E0.26554 F3600
+G1 E-1.00000 F2100
+G0 X105.900 Y112.264 F9000
+G1 E1.00000 F2100
+G1 X107.736 Y114.100 E0.09716 F3600
+;LAYER:3
+G0 Z0.800 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X114.325 Y105.675 F9000
+G1 E1.00000 F2100
+G1 X114.325 Y114.325 E0.32366 F2400
+G1 X105.675 Y114.325 E0.32366 F2400
+G1 X105.675 Y105.675 E0.32366 F2400
+G1 X114.325 Y105.675 E0.32366 F2400
+G0 X114.775 Y105.225 F9000
+G1 X114.775 Y114.775 E0.35734 F2400
+G1 X105.225 Y114.775 E0.35734 F2400
+G1 X105.225 Y105.225 E0.35734 F2400
+G1 X114.775 Y105.225 E0.35734 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X111.810 Y114.100 F9000
+G1 E1.00000 F2100
+G1 X114.100 Y111.810 E0.12118 F3600
+G1 E-1.00000 F2100
+G0 X114.100 Y108.628 F9000
+G1 E1.00000 F2100
+G1 X108.628 Y114.100 E0.28956 F3600
+G1 E-1.00000 F2100
+G0 X105.900 Y113.646 F9000
+G1 E1.00000 F2100
+G1 X113.646 Y105.900 E0.40989 F3600
+G1 E-1.00000 F2100
+G0 X110.464 Y105.900 F9000
+G1 E1.00000 F2100
+G1 X105.900 Y110.464 E0.24151 F3600
+G1 E-1.00000 F2100
+G0 X105.900 Y107.282 F9000
+G1 E1.00000 F2100
+G1 X107.282 Y105.900 E0.07313 F3600
+;LAYER:4
+G0 Z1.000 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X114.325 Y105.675 F9000
+G1 E1.00000 F2100
+G1 X114.325 Y114.325 E0.32366 F2400
+G1 X105.675 Y114.325 E0.32366 F2400
+G1 X105.675 Y105.675 E0.32366 F2400
+G1 X114.325 Y105.675 E0.32366 F2400
+G0 X114.775 Y105.225 F9000
+G1 X114.775 Y114.775 E0.35734 F2400
+G1 X105.225 Y114.775 E0.35734 F2400
+G1 X105.225 Y105.225 E0.35734 F2400
+G1 X114.775 Y105.225 E0.35734 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X112.264 Y105.900 F9000
+G1 E1.00000 F2100
+G1 X114.100 Y107.736 E0.09716 F3600
+G1 E-1.00000 F2100
+G0 X114.100 Y110.918 F9000
+G1 E1.00000 F2100
+G1 X109.082 Y105.900 E0.26554 F3600
+G1 E-1.00000 F2100
+G0 X105.900 Y105.900 F9000
+G1 E1.00000 F2100
+G1 X114.100 Y114.100 E0.43392 F3600
+G1 E-1.00000 F2100
+G0 X110.918 Y114.100 F9000
+G1 E1.00000 F2100
+G1 X105.900 Y109.082 E0.26554 F3600
+G1 E-1.00000 F2100
+G0 X105.900 Y112.264 F9000
+G1 E1.00000 F2100
+G1 X107.736 Y114.100 E0.09716 F3600
+G1 E-1.00000 F2100
+;END
+; filament used 19.4 mm
+M104 S0 ; nozzle off
+M140 S0 ; bed off
+G91
+G1 Z10 F600 ; lift away from the part
+G90
+M84 ; motors off
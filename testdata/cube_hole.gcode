@@ -0,0 +1,516 @@
+; generated by blocked
+; layer height 0.200, width 0.450, 2 perimeters, infill 20%
+M140 S60 ; bed temperature
+M104 S210 ; nozzle temperature
+G28 ; home all axes
+M190 S60 ; wait for bed
+M109 S210 ; wait for nozzle
+G1 Z5 F3000
+G21 ; millimetres
+G90 ; absolute positions
+M83 ; relative extrusion
+;LAYER:0
+G0 Z0.200 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X119.325 Y100.675 F9000
+G1 E1.00000 F2100
+G1 X119.325 Y119.325 E0.69784 F1200
+G1 X100.675 Y119.325 E0.69784 F1200
+G1 X100.675 Y100.675 E0.69784 F1200
+G1 X119.325 Y100.675 E0.69784 F1200
+G1 E-1.00000 F2100
+G0 X114.675 Y114.675 F9000
+G1 E1.00000 F2100
+G1 X114.675 Y105.325 E0.34986 F1200
+G1 X105.325 Y105.325 E0.34986 F1200
+G1 X105.325 Y114.675 E0.34986 F1200
+G1 X114.675 Y114.675 E0.34986 F1200
+G1 E-1.00000 F2100
+G0 X119.775 Y100.225 F9000
+G1 E1.00000 F2100
+G1 X119.775 Y119.775 E0.73152 F1200
+G1 X100.225 Y119.775 E0.73152 F1200
+G1 X100.225 Y100.225 E0.73152 F1200
+G1 X119.775 Y100.225 E0.73152 F1200
+G1 E-1.00000 F2100
+G0 X114.225 Y114.225 F9000
+G1 E1.00000 F2100
+G1 X114.225 Y105.775 E0.31618 F1200
+G1 X105.775 Y105.775 E0.31618 F1200
+G1 X105.775 Y114.225 E0.31618 F1200
+G1 X114.225 Y114.225 E0.31618 F1200
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X119.100 Y103.190 F9000
+G1 E1.00000 F2100
+G1 X116.810 Y100.900 E0.12118 F1200
+G1 E-1.00000 F2100
+G0 X113.628 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y106.372 E0.28956 F1200
+G1 E-1.00000 F2100
+G0 X119.100 Y109.554 F9000
+G1 E1.00000 F2100
+G1 X114.900 Y105.354 E0.22225 F1200
+G0 X114.646 Y105.100 F9000
+G1 X110.446 Y100.900 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X107.264 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X111.464 Y105.100 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X114.900 Y108.536 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y112.736 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X119.100 Y115.918 F9000
+G1 E1.00000 F2100
+G1 X114.900 Y111.718 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X108.282 Y105.100 F9000
+G1 E1.00000 F2100
+G1 X104.082 Y100.900 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X100.900 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X105.100 Y105.100 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X114.900 Y114.900 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y119.100 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X115.918 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X111.718 Y114.900 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X105.100 Y108.282 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y104.082 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X100.900 Y107.264 F9000
+G1 E1.00000 F2100
+G1 X105.100 Y111.464 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X108.536 Y114.900 F9000
+G1 E1.00000 F2100
+G1 X112.736 Y119.100 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X109.554 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X105.354 Y114.900 E0.22225 F1200
+G0 X105.100 Y114.646 F9000
+G1 X100.900 Y110.446 E0.22225 F1200
+G1 E-1.00000 F2100
+G0 X100.900 Y113.628 F9000
+G1 E1.00000 F2100
+G1 X106.372 Y119.100 E0.28956 F1200
+G1 E-1.00000 F2100
+G0 X103.190 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y116.810 E0.12118 F1200
+;LAYER:1
+G0 Z0.400 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X119.325 Y100.675 F9000
+G1 E1.00000 F2100
+G1 X119.325 Y119.325 E0.69784 F2400
+G1 X100.675 Y119.325 E0.69784 F2400
+G1 X100.675 Y100.675 E0.69784 F2400
+G1 X119.325 Y100.675 E0.69784 F2400
+G1 E-1.00000 F2100
+G0 X114.675 Y114.675 F9000
+G1 E1.00000 F2100
+G1 X114.675 Y105.325 E0.34986 F2400
+G1 X105.325 Y105.325 E0.34986 F2400
+G1 X105.325 Y114.675 E0.34986 F2400
+G1 X114.675 Y114.675 E0.34986 F2400
+G1 E-1.00000 F2100
+G0 X119.775 Y100.225 F9000
+G1 E1.00000 F2100
+G1 X119.775 Y119.775 E0.73152 F2400
+G1 X100.225 Y119.775 E0.73152 F2400
+G1 X100.225 Y100.225 E0.73152 F2400
+G1 X119.775 Y100.225 E0.73152 F2400
+G1 E-1.00000 F2100
+G0 X114.225 Y114.225 F9000
+G1 E1.00000 F2100
+G1 X114.225 Y105.775 E0.31618 F2400
+G1 X105.775 Y105.775 E0.31618 F2400
+G1 X105.775 Y114.225 E0.31618 F2400
+G1 X114.225 Y114.225 E0.31618 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X115.902 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y115.902 E0.16924 F3600
+G1 E-1.00000 F2100
+G0 X119.100 Y112.720 F9000
+G1 E1.00000 F2100
+G1 X112.720 Y119.100 E0.33762 F3600
+G1 E-1.00000 F2100
+G0 X109.538 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X113.738 Y114.900 E0.22225 F3600
+G0 X114.900 Y113.738 F9000
+G1 X119.100 Y109.538 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X119.100 Y106.356 F9000
+G1 E1.00000 F2100
+G1 X114.900 Y110.556 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X110.556 Y114.900 F9000
+G1 E1.00000 F2100
+G1 X106.356 Y119.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X103.174 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X107.374 Y114.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X114.900 Y107.374 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y103.174 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X118.192 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X113.992 Y105.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X105.100 Y113.992 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y118.192 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y115.010 F9000
+G1 E1.00000 F2100
+G1 X105.100 Y110.810 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X110.810 Y105.100 F9000
+G1 E1.00000 F2100
+G1 X115.010 Y100.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X111.828 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X107.628 Y105.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X105.100 Y107.628 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y111.828 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y108.646 F9000
+G1 E1.00000 F2100
+G1 X108.646 Y100.900 E0.40989 F3600
+G1 E-1.00000 F2100
+G0 X105.464 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y105.464 E0.24151 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y102.282 F9000
+G1 E1.00000 F2100
+G1 X102.282 Y100.900 E0.07313 F3600
+;LAYER:2
+G0 Z0.600 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X119.325 Y100.675 F9000
+G1 E1.00000 F2100
+G1 X119.325 Y119.325 E0.69784 F2400
+G1 X100.675 Y119.325 E0.69784 F2400
+G1 X100.675 Y100.675 E0.69784 F2400
+G1 X119.325 Y100.675 E0.69784 F2400
+G1 E-1.00000 F2100
+G0 X114.675 Y114.675 F9000
+G1 E1.00000 F2100
+G1 X114.675 Y105.325 E0.34986 F2400
+G1 X105.325 Y105.325 E0.34986 F2400
+G1 X105.325 Y114.675 E0.34986 F2400
+G1 X114.675 Y114.675 E0.34986 F2400
+G1 E-1.00000 F2100
+G0 X119.775 Y100.225 F9000
+G1 E1.00000 F2100
+G1 X119.775 Y119.775 E0.73152 F2400
+G1 X100.225 Y119.775 E0.73152 F2400
+G1 X100.225 Y100.225 E0.73152 F2400
+G1 X119.775 Y100.225 E0.73152 F2400
+G1 E-1.00000 F2100
+G0 X114.225 Y114.225 F9000
+G1 E1.00000 F2100
+G1 X114.225 Y105.775 E0.31618 F2400
+G1 X105.775 Y105.775 E0.31618 F2400
+G1 X105.775 Y114.225 E0.31618 F2400
+G1 X114.225 Y114.225 E0.31618 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X119.100 Y103.190 F9000
+G1 E1.00000 F2100
+G1 X116.810 Y100.900 E0.12118 F3600
+G1 E-1.00000 F2100
+G0 X113.628 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y106.372 E0.28956 F3600
+G1 E-1.00000 F2100
+G0 X119.100 Y109.554 F9000
+G1 E1.00000 F2100
+G1 X114.900 Y105.354 E0.22225 F3600
+G0 X114.646 Y105.100 F9000
+G1 X110.446 Y100.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X107.264 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X111.464 Y105.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X114.900 Y108.536 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y112.736 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X119.100 Y115.918 F9000
+G1 E1.00000 F2100
+G1 X114.900 Y111.718 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X108.282 Y105.100 F9000
+G1 E1.00000 F2100
+G1 X104.082 Y100.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X105.100 Y105.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X114.900 Y114.900 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y119.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X115.918 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X111.718 Y114.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X105.100 Y108.282 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y104.082 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y107.264 F9000
+G1 E1.00000 F2100
+G1 X105.100 Y111.464 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X108.536 Y114.900 F9000
+G1 E1.00000 F2100
+G1 X112.736 Y119.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X109.554 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X105.354 Y114.900 E0.22225 F3600
+G0 X105.100 Y114.646 F9000
+G1 X100.900 Y110.446 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y113.628 F9000
+G1 E1.00000 F2100
+G1 X106.372 Y119.100 E0.28956 F3600
+G1 E-1.00000 F2100
+G0 X103.190 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y116.810 E0.12118 F3600
+;LAYER:3
+G0 Z0.800 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X119.325 Y100.675 F9000
+G1 E1.00000 F2100
+G1 X119.325 Y119.325 E0.69784 F2400
+G1 X100.675 Y119.325 E0.69784 F2400
+G1 X100.675 Y100.675 E0.69784 F2400
+G1 X119.325 Y100.675 E0.69784 F2400
+G1 E-1.00000 F2100
+G0 X114.675 Y114.675 F9000
+G1 E1.00000 F2100
+G1 X114.675 Y105.325 E0.34986 F2400
+G1 X105.325 Y105.325 E0.34986 F2400
+G1 X105.325 Y114.675 E0.34986 F2400
+G1 X114.675 Y114.675 E0.34986 F2400
+G1 E-1.00000 F2100
+G0 X119.775 Y100.225 F9000
+G1 E1.00000 F2100
+G1 X119.775 Y119.775 E0.73152 F2400
+G1 X100.225 Y119.775 E0.73152 F2400
+G1 X100.225 Y100.225 E0.73152 F2400
+G1 X119.775 Y100.225 E0.73152 F2400
+G1 E-1.00000 F2100
+G0 X114.225 Y114.225 F9000
+G1 E1.00000 F2100
+G1 X114.225 Y105.775 E0.31618 F2400
+G1 X105.775 Y105.775 E0.31618 F2400
+G1 X105.775 Y114.225 E0.31618 F2400
+G1 X114.225 Y114.225 E0.31618 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X115.902 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y115.902 E0.16924 F3600
+G1 E-1.00000 F2100
+G0 X119.100 Y112.720 F9000
+G1 E1.00000 F2100
+G1 X112.720 Y119.100 E0.33762 F3600
+G1 E-1.00000 F2100
+G0 X109.538 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X113.738 Y114.900 E0.22225 F3600
+G0 X114.900 Y113.738 F9000
+G1 X119.100 Y109.538 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X119.100 Y106.356 F9000
+G1 E1.00000 F2100
+G1 X114.900 Y110.556 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X110.556 Y114.900 F9000
+G1 E1.00000 F2100
+G1 X106.356 Y119.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X103.174 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X107.374 Y114.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X114.900 Y107.374 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y103.174 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X118.192 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X113.992 Y105.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X105.100 Y113.992 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y118.192 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y115.010 F9000
+G1 E1.00000 F2100
+G1 X105.100 Y110.810 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X110.810 Y105.100 F9000
+G1 E1.00000 F2100
+G1 X115.010 Y100.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X111.828 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X107.628 Y105.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X105.100 Y107.628 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y111.828 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y108.646 F9000
+G1 E1.00000 F2100
+G1 X108.646 Y100.900 E0.40989 F3600
+G1 E-1.00000 F2100
+G0 X105.464 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y105.464 E0.24151 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y102.282 F9000
+G1 E1.00000 F2100
+G1 X102.282 Y100.900 E0.07313 F3600
+;LAYER:4
+G0 Z1.000 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X119.325 Y100.675 F9000
+G1 E1.00000 F2100
+G1 X119.325 Y119.325 E0.69784 F2400
+G1 X100.675 Y119.325 E0.69784 F2400
+G1 X100.675 Y100.675 E0.69784 F2400
+G1 X119.325 Y100.675 E0.69784 F2400
+G1 E-1.00000 F2100
+G0 X114.675 Y114.675 F9000
+G1 E1.00000 F2100
+G1 X114.675 Y105.325 E0.34986 F2400
+G1 X105.325 Y105.325 E0.34986 F2400
+G1 X105.325 Y114.675 E0.34986 F2400
+G1 X114.675 Y114.675 E0.34986 F2400
+G1 E-1.00000 F2100
+G0 X119.775 Y100.225 F9000
+G1 E1.00000 F2100
+G1 X119.775 Y119.775 E0.73152 F2400
+G1 X100.225 Y119.775 E0.73152 F2400
+G1 X100.225 Y100.225 E0.73152 F2400
+G1 X119.775 Y100.225 E0.73152 F2400
+G1 E-1.00000 F2100
+G0 X114.225 Y114.225 F9000
+G1 E1.00000 F2100
+G1 X114.225 Y105.775 E0.31618 F2400
+G1 X105.775 Y105.775 E0.31618 F2400
+G1 X105.775 Y114.225 E0.31618 F2400
+G1 X114.225 Y114.225 E0.31618 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X119.100 Y103.190 F9000
+G1 E1.00000 F2100
+G1 X116.810 Y100.900 E0.12118 F3600
+G1 E-1.00000 F2100
+G0 X113.628 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y106.372 E0.28956 F3600
+G1 E-1.00000 F2100
+G0 X119.100 Y109.554 F9000
+G1 E1.00000 F2100
+G1 X114.900 Y105.354 E0.22225 F3600
+G0 X114.646 Y105.100 F9000
+G1 X110.446 Y100.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X107.264 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X111.464 Y105.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X114.900 Y108.536 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y112.736 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X119.100 Y115.918 F9000
+G1 E1.00000 F2100
+G1 X114.900 Y111.718 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X108.282 Y105.100 F9000
+G1 E1.00000 F2100
+G1 X104.082 Y100.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y100.900 F9000
+G1 E1.00000 F2100
+G1 X105.100 Y105.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X114.900 Y114.900 F9000
+G1 E1.00000 F2100
+G1 X119.100 Y119.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X115.918 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X111.718 Y114.900 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X105.100 Y108.282 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y104.082 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y107.264 F9000
+G1 E1.00000 F2100
+G1 X105.100 Y111.464 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X108.536 Y114.900 F9000
+G1 E1.00000 F2100
+G1 X112.736 Y119.100 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X109.554 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X105.354 Y114.900 E0.22225 F3600
+G0 X105.100 Y114.646 F9000
+G1 X100.900 Y110.446 E0.22225 F3600
+G1 E-1.00000 F2100
+G0 X100.900 Y113.628 F9000
+G1 E1.00000 F2100
+G1 X106.372 Y119.100 E0.28956 F3600
+G1 E-1.00000 F2100
+G0 X103.190 Y119.100 F9000
+G1 E1.00000 F2100
+G1 X100.900 Y116.810 E0.12118 F3600
+G1 E-1.00000 F2100
+;END
+; filament used 61.5 mm
+M104 S0 ; nozzle off
+M140 S0 ; bed off
+G91
+G1 Z10 F600 ; lift away from the part
+G90
+M84 ; motors off
@@ -0,0 +1,235 @@
+; generated by blocked
+; layer height 0.200, width 0.450, 2 perimeters, infill 20%
+M140 S60 ; bed temperature
+M104 S210 ; nozzle temperature
+G28 ; home all axes
+M190 S60 ; wait for bed
+M109 S210 ; wait for nozzle
+G1 Z5 F3000
+G21 ; millimetres
+G90 ; absolute positions
+M83 ; relative extrusion
+;LAYER:0
+G0 Z0.200 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X112.825 Y104.175 F9000
+G1 E1.00000 F2100
+G1 X112.825 Y112.825 E0.32366 F1200
+G1 X104.175 Y112.825 E0.32366 F1200
+G1 X104.175 Y104.175 E0.32366 F1200
+G1 X112.825 Y104.175 E0.32366 F1200
+G0 X113.275 Y103.725 F9000
+G1 X113.275 Y113.275 E0.35734 F1200
+G1 X103.725 Y113.275 E0.35734 F1200
+G1 X103.725 Y103.725 E0.35734 F1200
+G1 X113.275 Y103.725 E0.35734 F1200
+G1 E-1.00000 F2100
+G0 X116.275 Y115.725 F9000
+G1 E1.00000 F2100
+G1 X116.275 Y116.275 E0.02058 F1200
+G1 X115.725 Y116.275 E0.02058 F1200
+G1 X115.725 Y115.725 E0.02058 F1200
+G1 X116.275 Y115.725 E0.02058 F1200
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X110.764 Y104.400 F9000
+G1 E1.00000 F2100
+G1 X112.600 Y106.236 E0.09716 F1200
+G1 E-1.00000 F2100
+G0 X112.600 Y109.418 F9000
+G1 E1.00000 F2100
+G1 X107.582 Y104.400 E0.26554 F1200
+G1 E-1.00000 F2100
+G0 X104.400 Y104.400 F9000
+G1 E1.00000 F2100
+G1 X112.600 Y112.600 E0.43392 F1200
+G1 E-1.00000 F2100
+G0 X109.418 Y112.600 F9000
+G1 E1.00000 F2100
+G1 X104.400 Y107.582 E0.26554 F1200
+G1 E-1.00000 F2100
+G0 X104.400 Y110.764 F9000
+G1 E1.00000 F2100
+G1 X106.236 Y112.600 E0.09716 F1200
+;LAYER:1
+G0 Z0.400 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X112.825 Y104.175 F9000
+G1 E1.00000 F2100
+G1 X112.825 Y112.825 E0.32366 F2400
+G1 X104.175 Y112.825 E0.32366 F2400
+G1 X104.175 Y104.175 E0.32366 F2400
+G1 X112.825 Y104.175 E0.32366 F2400
+G0 X113.275 Y103.725 F9000
+G1 X113.275 Y113.275 E0.35734 F2400
+G1 X103.725 Y113.275 E0.35734 F2400
+G1 X103.725 Y103.725 E0.35734 F2400
+G1 X113.275 Y103.725 E0.35734 F2400
+G1 E-1.00000 F2100
+G0 X116.275 Y115.725 F9000
+G1 E1.00000 F2100
+G1 X116.275 Y116.275 E0.02058 F2400
+G1 X115.725 Y116.275 E0.02058 F2400
+G1 X115.725 Y115.725 E0.02058 F2400
+G1 X116.275 Y115.725 E0.02058 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X110.310 Y112.600 F9000
+G1 E1.00000 F2100
+G1 X112.600 Y110.310 E0.12118 F3600
+G1 E-1.00000 F2100
+G0 X112.600 Y107.128 F9000
+G1 E1.00000 F2100
+G1 X107.128 Y112.600 E0.28956 F3600
+G1 E-1.00000 F2100
+G0 X104.400 Y112.146 F9000
+G1 E1.00000 F2100
+G1 X112.146 Y104.400 E0.40989 F3600
+G1 E-1.00000 F2100
+G0 X108.964 Y104.400 F9000
+G1 E1.00000 F2100
+G1 X104.400 Y108.964 E0.24151 F3600
+G1 E-1.00000 F2100
+G0 X104.400 Y105.782 F9000
+G1 E1.00000 F2100
+G1 X105.782 Y104.400 E0.07313 F3600
+;LAYER:2
+G0 Z0.600 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X112.825 Y104.175 F9000
+G1 E1.00000 F2100
+G1 X112.825 Y112.825 E0.32366 F2400
+G1 X104.175 Y112.825 E0.32366 F2400
+G1 X104.175 Y104.175 E0.32366 F2400
+G1 X112.825 Y104.175 E0.32366 F2400
+G0 X113.275 Y103.725 F9000
+G1 X113.275 Y113.275 E0.35734 F2400
+G1 X103.725 Y113.275 E0.35734 F2400
+G1 X103.725 Y103.725 E0.35734 F2400
+G1 X113.275 Y103.725 E0.35734 F2400
+G1 E-1.00000 F2100
+G0 X116.275 Y115.725 F9000
+G1 E1.00000 F2100
+G1 X116.275 Y116.275 E0.02058 F2400
+G1 X115.725 Y116.275 E0.02058 F2400
+G1 X115.725 Y115.725 E0.02058 F2400
+G1 X116.275 Y115.725 E0.02058 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X110.764 Y104.400 F9000
+G1 E1.00000 F2100
+G1 X112.600 Y106.236 E0.09716 F3600
+G1 E-1.00000 F2100
+G0 X112.600 Y109.418 F9000
+G1 E1.00000 F2100
+G1 X107.582 Y104.400 E0.26554 F3600
+G1 E-1.00000 F2100
+G0 X104.400 Y104.400 F9000
+G1 E1.00000 F2100
+G1 X112.600 Y112.600 E0.43392 F3600
+G1 E-1.00000 F2100
+G0 X109.418 Y112.600 F9000
+G1 E1.00000 F2100
+G1 X104.400 Y107.582 E0.26554 F3600
+G1 E-1.00000 F2100
+G0 X104.400 Y110.764 F9000
+G1 E1.00000 F2100
+G1 X106.236 Y112.600 E0.09716 F3600
+;LAYER:3
+G0 Z0.800 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X112.825 Y104.175 F9000
+G1 E1.00000 F2100
+G1 X112.825 Y112.825 E0.32366 F2400
+G1 X104.175 Y112.825 E0.32366 F2400
+G1 X104.175 Y104.175 E0.32366 F2400
+G1 X112.825 Y104.175 E0.32366 F2400
+G0 X113.275 Y103.725 F9000
+G1 X113.275 Y113.275 E0.35734 F2400
+G1 X103.725 Y113.275 E0.35734 F2400
+G1 X103.725 Y103.725 E0.35734 F2400
+G1 X113.275 Y103.725 E0.35734 F2400
+G1 E-1.00000 F2100
+G0 X116.275 Y115.725 F9000
+G1 E1.00000 F2100
+G1 X116.275 Y116.275 E0.02058 F2400
+G1 X115.725 Y116.275 E0.02058 F2400
+G1 X115.725 Y115.725 E0.02058 F2400
+G1 X116.275 Y115.725 E0.02058 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X110.310 Y112.600 F9000
+G1 E1.00000 F2100
+G1 X112.600 Y110.310 E0.12118 F3600
+G1 E-1.00000 F2100
+G0 X112.600 Y107.128 F9000
+G1 E1.00000 F2100
+G1 X107.128 Y112.600 E0.28956 F3600
+G1 E-1.00000 F2100
+G0 X104.400 Y112.146 F9000
+G1 E1.00000 F2100
+G1 X112.146 Y104.400 E0.40989 F3600
+G1 E-1.00000 F2100
+G0 X108.964 Y104.400 F9000
+G1 E1.00000 F2100
+G1 X104.400 Y108.964 E0.24151 F3600
+G1 E-1.00000 F2100
+G0 X104.400 Y105.782 F9000
+G1 E1.00000 F2100
+G1 X105.782 Y104.400 E0.07313 F3600
+;LAYER:4
+G0 Z1.000 F9000
+;TYPE:PERIMETER
+G1 E-1.00000 F2100
+G0 X112.825 Y104.175 F9000
+G1 E1.00000 F2100
+G1 X112.825 Y112.825 E0.32366 F2400
+G1 X104.175 Y112.825 E0.32366 F2400
+G1 X104.175 Y104.175 E0.32366 F2400
+G1 X112.825 Y104.175 E0.32366 F2400
+G0 X113.275 Y103.725 F9000
+G1 X113.275 Y113.275 E0.35734 F2400
+G1 X103.725 Y113.275 E0.35734 F2400
+G1 X103.725 Y103.725 E0.35734 F2400
+G1 X113.275 Y103.725 E0.35734 F2400
+G1 E-1.00000 F2100
+G0 X116.275 Y115.725 F9000
+G1 E1.00000 F2100
+G1 X116.275 Y116.275 E0.02058 F2400
+G1 X115.725 Y116.275 E0.02058 F2400
+G1 X115.725 Y115.725 E0.02058 F2400
+G1 X116.275 Y115.725 E0.02058 F2400
+;TYPE:INFILL
+G1 E-1.00000 F2100
+G0 X110.764 Y104.400 F9000
+G1 E1.00000 F2100
+G1 X112.600 Y106.236 E0.09716 F3600
+G1 E-1.00000 F2100
+G0 X112.600 Y109.418 F9000
+G1 E1.00000 F2100
+G1 X107.582 Y104.400 E0.26554 F3600
+G1 E-1.00000 F2100
+G0 X104.400 Y104.400 F9000
+G1 E1.00000 F2100
+G1 X112.600 Y112.600 E0.43392 F3600
+G1 E-1.00000 F2100
+G0 X109.418 Y112.600 F9000
+G1 E1.00000 F2100
+G1 X104.400 Y107.582 E0.26554 F3600
+G1 E-1.00000 F2100
+G0 X104.400 Y110.764 F9000
+G1 E1.00000 F2100
+G1 X106.236 Y112.600 E0.09716 F3600
+G1 E-1.00000 F2100
+;END
+; filament used 19.8 mm
+M104 S0 ; nozzle off
+M140 S0 ; bed off
+G91
+G1 Z10 F600 ; lift away from the part
+G90
+M84 ; motors off
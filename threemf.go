@@ -0,0 +1,633 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	threeMFNamespace    = "http://schemas.microsoft.com/3dmanufacturing/core/2015/02"
+	threeMFRelationship = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"
+	threeMFModelPath    = "3D/3dmodel.model"
+)
+
+// ThreeMF is a 3MF build plate: every
+// mesh to print, placed by its build
+// item transform, in the package unit.
+type ThreeMF struct {
+	// Unit is one of micron, millimeter,
+	// centimeter, inch, foot or meter.
+	Unit  string
+	Items []*ThreeMFItem
+}
+
+// ThreeMFItem is one placed mesh of a
+// build; objects built from components
+// are flattened into one item per mesh.
+type ThreeMFItem struct {
+	Name      string
+	Transform mgl32.Mat4
+
+	// Material holds the base material
+	// colour of the object, nil if none.
+	Material *Material
+
+	// TriangleColors, when set, holds a base
+	// material colour for every triangle.
+	TriangleColors []mgl32.Vec4
+
+	*IndexedMesh
+}
+
+// threeMFUnits maps the model units
+// to their length in millimetres.
+var threeMFUnits = map[string]float32{
+	"micron":     0.001,
+	"millimeter": 1,
+	"centimeter": 10,
+	"inch":       25.4,
+	"foot":       304.8,
+	"meter":      1000,
+}
+
+// Millimeters
+// Length of one model unit in millimetres.
+func (t *ThreeMF) Millimeters() float32 {
+	if mm, ok := threeMFUnits[t.Unit]; ok {
+		return mm
+	}
+
+	return 1
+}
+
+// Open3MF
+// Reads every build item of a 3MF package.
+func Open3MF(file string) (*ThreeMF, error) {
+	r, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return read3MF(&r.Reader)
+}
+
+// Read3MF
+// Reads a 3MF package from memory or
+// any other random access source.
+func Read3MF(r io.ReaderAt, size int64) (*ThreeMF, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return read3MF(zr)
+}
+
+func read3MF(zr *zip.Reader) (*ThreeMF, error) {
+	modelPath := threeMFModelPath
+
+	var rels struct {
+		Relationships []struct {
+			Target string `xml:"Target,attr"`
+			Type   string `xml:"Type,attr"`
+		} `xml:"Relationship"`
+	}
+
+	if err := decode3MFPart(zr, "_rels/.rels", &rels); err == nil {
+		for _, rel := range rels.Relationships {
+			if rel.Type == threeMFRelationship {
+				modelPath = strings.TrimPrefix(rel.Target, "/")
+			}
+		}
+	}
+
+	var model threeMFModel
+	if err := decode3MFPart(zr, modelPath, &model); err != nil {
+		return nil, err
+	}
+
+	t := &ThreeMF{Unit: model.Unit}
+	if t.Unit == "" {
+		t.Unit = "millimeter"
+	}
+
+	r := threeMFResolver{
+		model:   &model,
+		objects: map[int]*threeMFObject{},
+		meshes:  map[int]*ThreeMFItem{},
+		colors:  map[int][]mgl32.Vec4{},
+	}
+
+	for i := range model.Resources.Objects {
+		object := &model.Resources.Objects[i]
+		r.objects[object.ID] = object
+	}
+
+	for _, group := range model.Resources.BaseMaterials {
+		for _, base := range group.Bases {
+			color, err := parse3MFColor(base.DisplayColor)
+			if err != nil {
+				return nil, fmt.Errorf("3mf: basematerials %d: %v", group.ID, err)
+			}
+			r.colors[group.ID] = append(r.colors[group.ID], color)
+		}
+	}
+
+	for _, item := range model.Build {
+		transform, err := parse3MFTransform(item.Transform)
+		if err != nil {
+			return nil, fmt.Errorf("3mf: build item %d: %v", item.ObjectID, err)
+		}
+
+		if err := r.resolve(item.ObjectID, transform, 0, &t.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func decode3MFPart(zr *zip.Reader, name string, v interface{}) error {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		if err := xml.NewDecoder(rc).Decode(v); err != nil {
+			return fmt.Errorf("3mf: %s: %v", name, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("3mf: missing part %s", name)
+}
+
+// threeMFResolver flattens the object
+// and component graph into build items.
+type threeMFResolver struct {
+	model   *threeMFModel
+	objects map[int]*threeMFObject
+	meshes  map[int]*ThreeMFItem
+	colors  map[int][]mgl32.Vec4
+}
+
+// resolve appends one item per mesh reachable from
+// the object; depth guards against component cycles.
+func (r *threeMFResolver) resolve(
+	id int, transform mgl32.Mat4,
+	depth int, items *[]*ThreeMFItem,
+) error {
+	object, ok := r.objects[id]
+	if !ok {
+		return fmt.Errorf("3mf: unknown object %d", id)
+	}
+
+	if depth > len(r.objects) {
+		return fmt.Errorf("3mf: component cycle through object %d", id)
+	}
+
+	for _, component := range object.Components {
+		local, err := parse3MFTransform(component.Transform)
+		if err != nil {
+			return fmt.Errorf("3mf: object %d: %v", id, err)
+		}
+
+		err = r.resolve(component.ObjectID, transform.Mul4(local), depth+1, items)
+		if err != nil {
+			return err
+		}
+	}
+
+	if object.Mesh == nil {
+		return nil
+	}
+
+	mesh, ok := r.meshes[id]
+	if !ok {
+		var err error
+		if mesh, err = r.mesh(object); err != nil {
+			return err
+		}
+		r.meshes[id] = mesh
+	}
+
+	item := *mesh
+	item.Transform = transform
+	*items = append(*items, &item)
+	return nil
+}
+
+func (r *threeMFResolver) mesh(object *threeMFObject) (*ThreeMFItem, error) {
+	item := &ThreeMFItem{
+		Name: object.Name,
+		IndexedMesh: &IndexedMesh{
+			Positions: make([]mgl32.Vec3, len(object.Mesh.Vertices)),
+			Indices:   make([]uint32, 0, len(object.Mesh.Triangles)*3),
+		},
+	}
+
+	for i, v := range object.Mesh.Vertices {
+		item.Positions[i] = mgl32.Vec3{v.X, v.Y, v.Z}
+	}
+
+	objectColor, hasColor, err := r.color(object.PID, object.PIndex)
+	if err != nil {
+		return nil, fmt.Errorf("3mf: object %d: %v", object.ID, err)
+	}
+
+	if hasColor {
+		item.Material = &Material{
+			Name:      object.Name,
+			Diffuse:   objectColor.Vec3(),
+			Specular:  DefaultMaterial.Specular,
+			Shininess: DefaultMaterial.Shininess,
+		}
+	}
+
+	colors := make([]mgl32.Vec4, 0, len(object.Mesh.Triangles))
+	varied := false
+
+	for i, t := range object.Mesh.Triangles {
+		for _, v := range [3]uint32{t.V1, t.V2, t.V3} {
+			if int(v) >= len(item.Positions) {
+				return nil, fmt.Errorf("3mf: object %d: triangle %d: vertex %d out of range", object.ID, i, v)
+			}
+			item.Indices = append(item.Indices, v)
+		}
+
+		pid := t.PID
+		if pid == "" {
+			pid = object.PID
+		}
+
+		color, ok, err := r.color(pid, t.P1)
+		if err != nil {
+			return nil, fmt.Errorf("3mf: object %d: triangle %d: %v", object.ID, i, err)
+		}
+
+		if !ok {
+			color = white
+			if hasColor {
+				color = objectColor
+			}
+		}
+
+		if len(colors) > 0 && color != colors[0] {
+			varied = true
+		}
+
+		colors = append(colors, color)
+	}
+
+	if varied {
+		item.TriangleColors = colors
+	}
+
+	return item, nil
+}
+
+// color looks up a base material colour;
+// ok is false when no property is set.
+func (r *threeMFResolver) color(pid, index string) (color mgl32.Vec4, ok bool, err error) {
+	if pid == "" {
+		return
+	}
+
+	id, err := strconv.Atoi(pid)
+	if err != nil {
+		return color, false, fmt.Errorf("malformed pid %q", pid)
+	}
+
+	colors, found := r.colors[id]
+	if !found {
+		// Property groups of other extensions,
+		// e.g. textures, carry no base colour.
+		return
+	}
+
+	i := 0
+	if index != "" {
+		if i, err = strconv.Atoi(index); err != nil {
+			return color, false, fmt.Errorf("malformed property index %q", index)
+		}
+	}
+
+	if i < 0 || i >= len(colors) {
+		return color, false, fmt.Errorf("base material %d:%d out of range", id, i)
+	}
+
+	return colors[i], true, nil
+}
+
+// NewThreeMFItem
+// Welds a solid into a build item in place,
+// keeping its header material and facet colours.
+func NewThreeMFItem(s *STL) *ThreeMFItem {
+	item := &ThreeMFItem{
+		Name:           s.Name,
+		Transform:      mgl32.Ident4(),
+		TriangleColors: s.FacetColors(),
+		IndexedMesh:    s.Indexed(DefaultWeldEpsilon),
+	}
+
+	if material, ok := s.HeaderMaterial(); ok {
+		item.Material = material
+	}
+
+	return item
+}
+
+// Renderable
+// Copies the mesh for display: triangle
+// colours become vertex colours and
+// smooth normals are computed.
+func (i *ThreeMFItem) Renderable() *IndexedMesh {
+	mesh := &IndexedMesh{
+		Positions: i.Positions,
+		Indices:   i.Indices,
+	}
+
+	if i.TriangleColors != nil {
+		mesh = mesh.WithTriangleColors(i.TriangleColors)
+	}
+
+	mesh.SmoothNormals(DefaultCreaseAngle)
+	return mesh
+}
+
+// Save3MF
+// Writes the build plate as a 3MF package.
+func (t *ThreeMF) Save3MF(file string) (err error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return t.Write3MF(f)
+}
+
+// Write3MF
+// Writes the package parts; items sharing
+// an IndexedMesh share one object, and all
+// colours go into a single basematerials group.
+func (t *ThreeMF) Write3MF(w io.Writer) error {
+	model := threeMFModel{
+		Xmlns: threeMFNamespace,
+		Unit:  t.Unit,
+	}
+
+	const materialsID = 1
+	materials := threeMFBaseMaterials{ID: materialsID}
+	colorIndex := map[mgl32.Vec4]int{}
+	colorOf := func(c mgl32.Vec4) string {
+		i, ok := colorIndex[c]
+		if !ok {
+			i = len(materials.Bases)
+			colorIndex[c] = i
+			materials.Bases = append(materials.Bases, threeMFBase{
+				Name:         fmt.Sprintf("color %d", i),
+				DisplayColor: format3MFColor(c),
+			})
+		}
+		return strconv.Itoa(i)
+	}
+
+	objects := map[*IndexedMesh]int{}
+	nextID := materialsID + 1
+
+	for _, item := range t.Items {
+		id, ok := objects[item.IndexedMesh]
+		if !ok {
+			id = nextID
+			nextID++
+			objects[item.IndexedMesh] = id
+
+			object := threeMFObject{
+				ID:   id,
+				Type: "model",
+				Name: item.Name,
+				Mesh: &threeMFMesh{
+					Vertices:  make([]threeMFVertex, len(item.Positions)),
+					Triangles: make([]threeMFTriangle, len(item.Indices)/3),
+				},
+			}
+
+			if item.Material != nil {
+				object.PID = strconv.Itoa(materialsID)
+				object.PIndex = colorOf(item.Material.Diffuse.Vec4(1))
+			}
+
+			for i, p := range item.Positions {
+				object.Mesh.Vertices[i] = threeMFVertex{X: p[0], Y: p[1], Z: p[2]}
+			}
+
+			for i := range object.Mesh.Triangles {
+				triangle := &object.Mesh.Triangles[i]
+				triangle.V1 = item.Indices[i*3]
+				triangle.V2 = item.Indices[i*3+1]
+				triangle.V3 = item.Indices[i*3+2]
+
+				if i < len(item.TriangleColors) {
+					triangle.PID = strconv.Itoa(materialsID)
+					triangle.P1 = colorOf(item.TriangleColors[i])
+				}
+			}
+
+			model.Resources.Objects = append(model.Resources.Objects, object)
+		}
+
+		model.Build = append(model.Build, threeMFBuildItem{
+			ObjectID:  id,
+			Transform: format3MFTransform(item.Transform),
+		})
+	}
+
+	if len(materials.Bases) > 0 {
+		model.Resources.BaseMaterials = []threeMFBaseMaterials{materials}
+	}
+
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name string
+		v    interface{}
+	}{
+		{"[Content_Types].xml", threeMFContentTypes},
+		{"_rels/.rels", threeMFRels},
+		{threeMFModelPath, model},
+	}
+
+	for _, part := range parts {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(pw, xml.Header); err != nil {
+			return err
+		}
+
+		if s, ok := part.v.(string); ok {
+			_, err = io.WriteString(pw, s)
+		} else {
+			err = xml.NewEncoder(pw).Encode(part.v)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+var threeMFContentTypes = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="model" ContentType="application/vnd.ms-package.3dmanufacturing-3dmodel+xml"/>` +
+	`</Types>`
+
+var threeMFRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Target="/` + threeMFModelPath + `" Id="rel0" Type="` + threeMFRelationship + `"/>` +
+	`</Relationships>`
+
+// parse3MFTransform
+// Converts the 12 value row-vector matrix of 3MF
+// ("m00 m01 m02 m10 ... m32") into the column-vector
+// convention of mgl32; its rows become our columns.
+func parse3MFTransform(s string) (mgl32.Mat4, error) {
+	m := mgl32.Ident4()
+	if s == "" {
+		return m, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) != 12 {
+		return m, fmt.Errorf("transform needs 12 values, has %d", len(fields))
+	}
+
+	for i, field := range fields {
+		f, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			return m, fmt.Errorf("malformed transform %q", s)
+		}
+		m[(i/3)*4+i%3] = float32(f)
+	}
+
+	return m, nil
+}
+
+func format3MFTransform(m mgl32.Mat4) string {
+	values := make([]string, 12)
+	for i := range values {
+		values[i] = strconv.FormatFloat(float64(m[(i/3)*4+i%3]), 'g', -1, 32)
+	}
+
+	return strings.Join(values, " ")
+}
+
+// parse3MFColor reads #RRGGBB or #RRGGBBAA.
+func parse3MFColor(s string) (color mgl32.Vec4, err error) {
+	if len(s) != 7 && len(s) != 9 || s[0] != '#' {
+		return color, fmt.Errorf("malformed color %q", s)
+	}
+
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color, fmt.Errorf("malformed color %q", s)
+	}
+
+	if len(s) == 7 {
+		v = v<<8 | 0xff
+	}
+
+	for i := range color {
+		color[i] = float32(v>>uint(24-i*8)&0xff) / 255
+	}
+
+	return color, nil
+}
+
+func format3MFColor(c mgl32.Vec4) string {
+	b := rgbaToBytes(c)
+	return fmt.Sprintf("#%02X%02X%02X%02X", b[0], b[1], b[2], b[3])
+}
+
+type threeMFModel struct {
+	XMLName   xml.Name `xml:"model"`
+	Xmlns     string   `xml:"xmlns,attr,omitempty"`
+	Unit      string   `xml:"unit,attr,omitempty"`
+	Resources struct {
+		BaseMaterials []threeMFBaseMaterials `xml:"basematerials"`
+		Objects       []threeMFObject        `xml:"object"`
+	} `xml:"resources"`
+	Build []threeMFBuildItem `xml:"build>item"`
+}
+
+type threeMFBaseMaterials struct {
+	ID    int           `xml:"id,attr"`
+	Bases []threeMFBase `xml:"base"`
+}
+
+type threeMFBase struct {
+	Name         string `xml:"name,attr"`
+	DisplayColor string `xml:"displaycolor,attr"`
+}
+
+type threeMFObject struct {
+	ID         int                `xml:"id,attr"`
+	Type       string             `xml:"type,attr,omitempty"`
+	Name       string             `xml:"name,attr,omitempty"`
+	PID        string             `xml:"pid,attr,omitempty"`
+	PIndex     string             `xml:"pindex,attr,omitempty"`
+	Mesh       *threeMFMesh       `xml:"mesh"`
+	Components []threeMFComponent `xml:"components>component"`
+}
+
+type threeMFMesh struct {
+	Vertices  []threeMFVertex   `xml:"vertices>vertex"`
+	Triangles []threeMFTriangle `xml:"triangles>triangle"`
+}
+
+type threeMFVertex struct {
+	X float32 `xml:"x,attr"`
+	Y float32 `xml:"y,attr"`
+	Z float32 `xml:"z,attr"`
+}
+
+type threeMFTriangle struct {
+	V1  uint32 `xml:"v1,attr"`
+	V2  uint32 `xml:"v2,attr"`
+	V3  uint32 `xml:"v3,attr"`
+	PID string `xml:"pid,attr,omitempty"`
+	P1  string `xml:"p1,attr,omitempty"`
+}
+
+type threeMFComponent struct {
+	ObjectID  int    `xml:"objectid,attr"`
+	Transform string `xml:"transform,attr,omitempty"`
+}
+
+type threeMFBuildItem struct {
+	ObjectID  int    `xml:"objectid,attr"`
+	Transform string `xml:"transform,attr,omitempty"`
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestThreeMFRoundTrip(t *testing.T) {
+	cube := NewThreeMFItem(prism(square(0, 10), nil, 10))
+	cube.Name = "cube"
+	cube.Material = &Material{Diffuse: mgl32.Vec3{1, 0, 0}}
+
+	frame := NewThreeMFItem(prism(square(0, 20), square(6, 14), 5))
+	frame.Name = "frame"
+	frame.Transform = mgl32.Translate3D(30, 0, 0).Mul4(mgl32.HomogRotate3DZ(mgl32.DegToRad(90)))
+	frame.TriangleColors = make([]mgl32.Vec4, len(frame.Indices)/3)
+	for i := range frame.TriangleColors {
+		frame.TriangleColors[i] = mgl32.Vec4{0, float32(i % 2), 1, 1}
+	}
+
+	// A second copy of the cube shares its object.
+	copied := *cube
+	copied.Transform = mgl32.Translate3D(0, 30, 0)
+
+	plate := &ThreeMF{Unit: "inch", Items: []*ThreeMFItem{cube, frame, &copied}}
+
+	var buf bytes.Buffer
+	if err := plate.Write3MF(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := Read3MF(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if read.Unit != "inch" || read.Millimeters() != 25.4 {
+		t.Errorf("unit %q", read.Unit)
+	}
+
+	if len(read.Items) != len(plate.Items) {
+		t.Fatalf("%d items, want %d", len(read.Items), len(plate.Items))
+	}
+
+	for i, want := range plate.Items {
+		got := read.Items[i]
+		if got.Name != want.Name {
+			t.Errorf("item %d: name %q, want %q", i, got.Name, want.Name)
+		}
+		if !got.Transform.ApproxEqualThreshold(want.Transform, 1e-5) {
+			t.Errorf("%s: transform %v, want %v", want.Name, got.Transform, want.Transform)
+		}
+		if len(got.Positions) != len(want.Positions) || len(got.Indices) != len(want.Indices) {
+			t.Fatalf("%s: %d vertices %d indices, want %d and %d", want.Name,
+				len(got.Positions), len(got.Indices), len(want.Positions), len(want.Indices))
+		}
+		for j, p := range want.Positions {
+			if !got.Positions[j].ApproxEqual(p) {
+				t.Fatalf("%s: vertex %d at %v, want %v", want.Name, j, got.Positions[j], p)
+			}
+		}
+	}
+
+	if m := read.Items[0].Material; m == nil || !m.Diffuse.ApproxEqual(mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("cube material %+v", m)
+	}
+
+	colors := read.Items[1].TriangleColors
+	if len(colors) != len(frame.TriangleColors) {
+		t.Fatalf("%d triangle colours, want %d", len(colors), len(frame.TriangleColors))
+	}
+	for i, c := range frame.TriangleColors {
+		if !colors[i].ApproxEqualThreshold(c, 1e-2) {
+			t.Fatalf("triangle %d colour %v, want %v", i, colors[i], c)
+		}
+	}
+
+	if read.Items[0].IndexedMesh != read.Items[2].IndexedMesh {
+		t.Error("copies of an object do not share its mesh")
+	}
+}
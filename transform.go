@@ -0,0 +1,180 @@
+package main
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hschendel/stl"
+)
+
+// Transform composes affine edits of a solid;
+// each edit applies after the previous ones.
+// The result is either baked into the facets
+// with Bake() or used as a Mesh.Transform,
+// leaving the solid untouched.
+type Transform struct {
+	Matrix mgl32.Mat4
+	solid  *STL
+}
+
+// Edit
+// Starts an identity Transform of the solid.
+func (s *STL) Edit() *Transform {
+	return &Transform{Matrix: mgl32.Ident4(), solid: s}
+}
+
+// Then
+// Applies m after the edits so far.
+func (t *Transform) Then(m mgl32.Mat4) *Transform {
+	t.Matrix = m.Mul4(t.Matrix)
+	return t
+}
+
+func (t *Transform) Translate(v mgl32.Vec3) *Transform {
+	return t.Then(mgl32.Translate3D(v[0], v[1], v[2]))
+}
+
+// Rotate
+// Turns degrees about axis through the origin,
+// counter-clockwise looking down the axis.
+func (t *Transform) Rotate(degrees float32, axis mgl32.Vec3) *Transform {
+	return t.Then(mgl32.HomogRotate3D(mgl32.DegToRad(degrees), axis.Normalize()))
+}
+
+// RotateEuler
+// Turns about X, then Y, then Z, in degrees.
+func (t *Transform) RotateEuler(x, y, z float32) *Transform {
+	return t.Then(mgl32.AnglesToQuat(
+		mgl32.DegToRad(z), mgl32.DegToRad(y), mgl32.DegToRad(x), mgl32.ZYX,
+	).Mat4())
+}
+
+// Mirror
+// Reflects through the plane at the origin
+// with the given normal.
+func (t *Transform) Mirror(normal mgl32.Vec3) *Transform {
+	n := normal.Normalize()
+	m := mgl32.Ident4()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m.Set(i, j, m.At(i, j)-2*n[i]*n[j])
+		}
+	}
+
+	return t.Then(m)
+}
+
+// Scale
+// Scales each axis about the origin.
+func (t *Transform) Scale(v mgl32.Vec3) *Transform {
+	return t.Then(mgl32.Scale3D(v[0], v[1], v[2]))
+}
+
+// CenterOnOrigin
+// Moves the centre of the transformed
+// bounding box onto the origin.
+func (t *Transform) CenterOnOrigin() *Transform {
+	b := t.Bounds()
+	if b.Empty() {
+		return t
+	}
+
+	return t.Translate(b.Center().Mul(-1))
+}
+
+// PlaceOnBed
+// Moves the transformed solid along Z
+// so its lowest vertex sits at Z = 0.
+func (t *Transform) PlaceOnBed() *Transform {
+	b := t.Bounds()
+	if b.Empty() {
+		return t
+	}
+
+	return t.Translate(mgl32.Vec3{0, 0, -b.Min[2]})
+}
+
+// Bounds
+// The axis-aligned box of the solid
+// as the transform would place it.
+func (t *Transform) Bounds() Bounds {
+	b := EmptyBounds()
+	for _, triangle := range t.solid.Triangles {
+		for _, v := range triangle.Vertices {
+			b = b.Extend(mgl32.TransformCoordinate(mgl32.Vec3(v), t.Matrix))
+		}
+	}
+
+	return b
+}
+
+// Mirrors
+// Whether the transform turns the solid inside
+// out, so winding (and face culling) flips.
+func (t *Transform) Mirrors() bool {
+	return t.Matrix.Det() < 0
+}
+
+// Bake
+// Applies the transform to the facets of the
+// solid and resets it to the identity.
+func (t *Transform) Bake() {
+	t.solid.Transform(t.Matrix)
+	t.Matrix = mgl32.Ident4()
+}
+
+// Transform
+// Applies m to every vertex, reversing the winding
+// when m mirrors so facets keep facing outward.
+func (s *STL) Transform(m mgl32.Mat4) {
+	mirrors := m.Det() < 0
+
+	for i := range s.Triangles {
+		v := &s.Triangles[i].Vertices
+		for j := range v {
+			v[j] = stl.Vec3(mgl32.TransformCoordinate(mgl32.Vec3(v[j]), m))
+		}
+
+		if mirrors {
+			v[1], v[2] = v[2], v[1]
+		}
+		s.Triangles[i].Normal = windingNormal(s.Triangles[i])
+	}
+
+	s.modified = true
+}
+
+func (s *STL) Translate(v mgl32.Vec3) {
+	s.Edit().Translate(v).Bake()
+}
+
+// Rotate
+// See Transform.Rotate().
+func (s *STL) Rotate(degrees float32, axis mgl32.Vec3) {
+	s.Edit().Rotate(degrees, axis).Bake()
+}
+
+// RotateEuler
+// See Transform.RotateEuler().
+func (s *STL) RotateEuler(x, y, z float32) {
+	s.Edit().RotateEuler(x, y, z).Bake()
+}
+
+// Mirror
+// See Transform.Mirror().
+func (s *STL) Mirror(normal mgl32.Vec3) {
+	s.Edit().Mirror(normal).Bake()
+}
+
+// ScaleXYZ
+// Scales each axis separately; see Scale()
+// for the uniform case.
+func (s *STL) ScaleXYZ(v mgl32.Vec3) {
+	s.Edit().Scale(v).Bake()
+}
+
+func (s *STL) CenterOnOrigin() {
+	s.Edit().CenterOnOrigin().Bake()
+}
+
+func (s *STL) PlaceOnBed() {
+	s.Edit().PlaceOnBed().Bake()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// triangulatePolygon
+// Splits a simple planar polygon, convex or not,
+// into triangles by ear clipping. The result indexes
+// into points and keeps the winding of the polygon.
+func triangulatePolygon(points []mgl32.Vec3) (triangles [][3]int) {
+	n := len(points)
+	if n < 3 {
+		return nil
+	}
+
+	if n == 3 {
+		return [][3]int{{0, 1, 2}}
+	}
+
+	// Project onto the plane most facing the
+	// Newell normal; the cyclic axis order keeps
+	// the polygon counter-clockwise when sign > 0.
+	normal := newellNormal(points)
+	axis := 0
+	for i := 1; i < 3; i++ {
+		if math.Abs(float64(normal[i])) > math.Abs(float64(normal[axis])) {
+			axis = i
+		}
+	}
+
+	u, v := (axis+1)%3, (axis+2)%3
+	sign := float32(1)
+	if normal[axis] < 0 {
+		sign = -1
+	}
+
+	flat := make([]mgl32.Vec2, n)
+	for i, p := range points {
+		flat[i] = mgl32.Vec2{p[u], p[v] * sign}
+	}
+
+	remaining := make([]int, n)
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	for len(remaining) > 3 {
+		ear := -1
+		count := len(remaining)
+
+		for i := 0; i < count && ear < 0; i++ {
+			a := remaining[(i+count-1)%count]
+			b := remaining[i]
+			c := remaining[(i+1)%count]
+
+			if cross2(flat[a], flat[b], flat[c]) <= 0 {
+				continue
+			}
+
+			ear = i
+			for _, p := range remaining {
+				if p != a && p != b && p != c &&
+					insideTriangle2(flat[p], flat[a], flat[b], flat[c]) {
+					ear = -1
+					break
+				}
+			}
+		}
+
+		// Not a simple polygon; fall
+		// back to fanning what is left.
+		if ear < 0 {
+			for i := 1; i+1 < count; i++ {
+				triangles = append(triangles, [3]int{
+					remaining[0], remaining[i], remaining[i+1],
+				})
+			}
+
+			return
+		}
+
+		triangles = append(triangles, [3]int{
+			remaining[(ear+count-1)%count],
+			remaining[ear],
+			remaining[(ear+1)%count],
+		})
+		remaining = append(remaining[:ear], remaining[ear+1:]...)
+	}
+
+	return append(triangles, [3]int{remaining[0], remaining[1], remaining[2]})
+}
+
+// newellNormal returns the (unnormalised) normal of a
+// possibly non-planar polygon by Newell's method.
+func newellNormal(points []mgl32.Vec3) (normal mgl32.Vec3) {
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		normal[0] += (p[1] - q[1]) * (p[2] + q[2])
+		normal[1] += (p[2] - q[2]) * (p[0] + q[0])
+		normal[2] += (p[0] - q[0]) * (p[1] + q[1])
+	}
+
+	return
+}
+
+func cross2(a, b, c mgl32.Vec2) float32 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+func insideTriangle2(p, a, b, c mgl32.Vec2) bool {
+	return cross2(a, b, p) >= 0 &&
+		cross2(b, c, p) >= 0 &&
+		cross2(c, a, p) >= 0
+}
@@ -16,3 +16,7 @@ func GenVertexArray() (vao VertexArrayObject) {
 func (vao VertexArrayObject) BindVertexArray() {
 	gl.BindVertexArray(uint32(vao))
 }
+
+func (vao VertexArrayObject) DeleteVertexArray() {
+	gl.DeleteVertexArrays(1, (*uint32)(unsafe.Pointer(&vao)))
+}
@@ -28,9 +28,19 @@ type Window struct {
 		cursorPos       []glfw.CursorPosCallback
 		mouseButton     []glfw.MouseButtonCallback
 		scroll          []glfw.ScrollCallback
+		drop            []glfw.DropCallback
 		run             []func(*glfw.Window)
+		update          []func(dt float64)
 		draw            []func(*glfw.Window)
 	}
+
+	// status holds the HUD fields shown
+	// after Title, in order of first use.
+	status []windowStatus
+}
+
+type windowStatus struct {
+	name, text string
 }
 
 func NewWindow(
@@ -138,6 +148,8 @@ func (w *Window) Run() {
 		w.callMouseButton)
 	w.Window.SetFramebufferSizeCallback(
 		w.callFramebufferSize)
+	w.Window.SetDropCallback(
+		w.callDrop)
 
 	// Show the window and contextualize
 	w.Window.Show()
@@ -206,8 +218,7 @@ func (w *Window) Run() {
 		// fixedDeltaTime, call Polygo's update callback to pass out a game
 		// update to the game deveoper's systems.
 		for accumulator >= fixedDeltaTime {
-			//polygo.callUpdate(fixedDeltaTime)
-			// @TODO: Determine what this is for
+			w.callUpdate(fixedDeltaTime)
 			accumulator -= fixedDeltaTime
 		}
 
@@ -258,6 +269,36 @@ func (w *Window) Aspect() float32 {
 	return float32(w.Width) / float32(w.Height)
 }
 
+// SetStatus
+// Shows text in the HUD under name, replacing
+// its previous text; empty text hides it. The
+// HUD is the window title, after Title.
+func (w *Window) SetStatus(name, text string) {
+	found := false
+	for i := range w.status {
+		if w.status[i].name == name {
+			w.status[i].text = text
+			found = true
+		}
+	}
+
+	if !found {
+		w.status = append(w.status, windowStatus{name, text})
+	}
+
+	if w.Window == nil {
+		return
+	}
+
+	title := w.Title
+	for _, s := range w.status {
+		if s.text != "" {
+			title += " | " + s.text
+		}
+	}
+	w.Window.SetTitle(title)
+}
+
 func (w *Window) OnKey(
 	cbs ...glfw.KeyCallback,
 ) {
@@ -298,6 +339,14 @@ func (w *Window) OnFramebufferSize(
 	)
 }
 
+func (w *Window) OnDrop(
+	cbs ...glfw.DropCallback,
+) {
+	w.callbacks.drop = append(
+		w.callbacks.drop, cbs...,
+	)
+}
+
 func (w *Window) OnRun(
 	cbs ...func(window *glfw.Window),
 ) {
@@ -306,6 +355,18 @@ func (w *Window) OnRun(
 	)
 }
 
+// OnUpdate
+// Registers callbacks stepped fixedDeltaTime
+// seconds at a time, whatever the frame rate;
+// for movement driven by held keys.
+func (w *Window) OnUpdate(
+	cbs ...func(dt float64),
+) {
+	w.callbacks.update = append(
+		w.callbacks.update, cbs...,
+	)
+}
+
 func (w *Window) OnDraw(
 	cbs ...func(window *glfw.Window),
 ) {
@@ -365,12 +426,27 @@ func (w *Window) callFramebufferSize(
 	}
 }
 
+func (w *Window) callDrop(
+	window *glfw.Window,
+	names []string,
+) {
+	for _, cb := range w.callbacks.drop {
+		cb(window, names)
+	}
+}
+
 func (w *Window) callRun(window *glfw.Window) {
 	for _, cb := range w.callbacks.run {
 		cb(window)
 	}
 }
 
+func (w *Window) callUpdate(dt float64) {
+	for _, cb := range w.callbacks.update {
+		cb(dt)
+	}
+}
+
 func (w *Window) callDraw(window *glfw.Window) {
 	for _, cb := range w.callbacks.draw {
 		cb(window)